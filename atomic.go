@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockTimeout is how long to wait for another aliasman process to finish
+// changing the store.
+const lockTimeout = 5 * time.Second
+
+// writeFileAtomic replaces the file at path with data so that readers and a
+// crash midway only ever see the old or the new contents. The file keeps its
+// mode, or gets mode if it is new. A symlink is followed, so that the file it
+// points to is replaced rather than the link.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	mode = fileMode(path, mode)
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Make the rename itself durable.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// lockStore takes the advisory lock that serializes changes to the store at
+// storePath between aliasman processes, and returns the function releasing
+// it. The lock is held on a separate file, since the store itself is
+// replaced on every write.
+func lockStore(storePath string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		return nil, err
+	}
+	unlock, err := lockFile(storePath + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("error locking %s: %w", storePath, err)
+	}
+	return unlock, nil
+}
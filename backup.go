@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxBackups is how many backups are kept; older ones are deleted.
+const maxBackups = 50
+
+// backup is a set of files saved before aliasman changed them. Each backup is
+// a directory under the state directory holding the copies and a manifest.
+type backup struct {
+	ID     string       `json:"-"`
+	Time   time.Time    `json:"time"`
+	Reason string       `json:"reason"`
+	Files  []backupFile `json:"files"`
+}
+
+type backupFile struct {
+	Path string      `json:"path"` // where the file was
+	Name string      `json:"name"` // the copy, inside the backup directory
+	Mode os.FileMode `json:"mode"`
+}
+
+const backupManifest = "manifest.json"
+
+func backupsDir(stateDir string) string {
+	return filepath.Join(stateDir, "backups")
+}
+
+// backupFiles saves the current contents of the existing files among paths
+// as a new backup, and deletes the oldest backups beyond maxBackups.
+func backupFiles(stateDir, reason string, paths []string) error {
+	b := backup{Time: time.Now(), Reason: reason}
+	b.ID = b.Time.Format("20060102-150405.000000")
+	dir := filepath.Join(backupsDir(stateDir), b.ID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		f := backupFile{Path: path, Name: fmt.Sprintf("%d-%s", i, filepath.Base(path)), Mode: fileMode(path, 0644)}
+		if err := os.WriteFile(filepath.Join(dir, f.Name), content, 0600); err != nil {
+			return err
+		}
+		b.Files = append(b.Files, f)
+	}
+	if len(b.Files) == 0 {
+		return os.Remove(dir)
+	}
+
+	manifest, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, backupManifest), manifest, 0600); err != nil {
+		return err
+	}
+	return pruneBackups(stateDir)
+}
+
+// listBackups returns the backups in stateDir, newest first.
+func listBackups(stateDir string) ([]backup, error) {
+	entries, err := os.ReadDir(backupsDir(stateDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		b, err := readBackup(stateDir, entry.Name())
+		if err != nil {
+			continue // an interrupted backup
+		}
+		backups = append(backups, b)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ID > backups[j].ID
+	})
+	return backups, nil
+}
+
+func readBackup(stateDir, id string) (backup, error) {
+	manifest, err := os.ReadFile(filepath.Join(backupsDir(stateDir), id, backupManifest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return backup{}, fmt.Errorf("there is no backup %q", id)
+	}
+	if err != nil {
+		return backup{}, err
+	}
+	b := backup{ID: id}
+	if err := json.Unmarshal(manifest, &b); err != nil {
+		return backup{}, fmt.Errorf("backup %s: %w", id, err)
+	}
+	return b, nil
+}
+
+func pruneBackups(stateDir string) error {
+	backups, err := listBackups(stateDir)
+	if err != nil || len(backups) <= maxBackups {
+		return err
+	}
+	for _, b := range backups[maxBackups:] {
+		if err := os.RemoveAll(filepath.Join(backupsDir(stateDir), b.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreChanges returns the changes that put the files of b back, leaving
+// out the files that already match.
+func restoreChanges(stateDir string, b backup) ([]fileChange, error) {
+	var changes []fileChange
+	for _, f := range b.Files {
+		saved, err := os.ReadFile(filepath.Join(backupsDir(stateDir), b.ID, f.Name))
+		if err != nil {
+			return nil, err
+		}
+		current, err := os.ReadFile(f.Path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if string(current) != string(saved) {
+			changes = append(changes, fileChange{Path: f.Path, Before: string(current), After: string(saved)})
+		}
+	}
+	return changes, nil
+}
+
+// restoreBackup puts the files of b back after backing up their current
+// versions. A restored store is saved through the store, so that the
+// generated files follow it.
+func restoreBackup(paths appPaths, b backup) error {
+	changes, err := restoreChanges(paths.StateDir, b)
+	if err != nil {
+		return err
+	}
+
+	var others []string
+	for _, c := range changes {
+		if c.Path != paths.Store {
+			others = append(others, c.Path)
+		}
+	}
+	reason := "restore " + b.ID
+	if err := backupFiles(paths.StateDir, reason, others); err != nil {
+		return fmt.Errorf("error backing up files: %w", err)
+	}
+
+	for _, c := range changes {
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return err
+		}
+		if c.Path != paths.Store {
+			if err := writeFileAtomic(c.Path, []byte(c.After), b.mode(c.Path)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var saved storeFile
+		if err := json.Unmarshal([]byte(c.After), &saved); err != nil {
+			return fmt.Errorf("the store in backup %s is damaged: %w", b.ID, err)
+		}
+		if !fileExists(paths.Store) {
+			if err := writeFileAtomic(paths.Store, []byte(c.After), b.mode(c.Path)); err != nil {
+				return err
+			}
+		}
+		err := editAliasStore(paths, reason, func(store *AliasStore) error {
+			store.data = saved
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b backup) mode(path string) os.FileMode {
+	for _, f := range b.Files {
+		if f.Path == path {
+			return f.Mode
+		}
+	}
+	return 0644
+}
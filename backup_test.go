@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	paths := testPaths(t)
+	for _, name := range []string{"ll", "la"} {
+		err := editAliasStore(paths, "add "+name, func(store *AliasStore) error {
+			return store.Add(Alias{Name: name, Type: "alias", Command: "ls -" + name[1:]})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	rc := filepath.Join(t.TempDir(), ".bashrc")
+	if err := os.WriteFile(rc, []byte("export A=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupFiles(paths.StateDir, "install", []string{rc, filepath.Join(t.TempDir(), "missing")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rc, []byte("export A=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := listBackups(paths.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reasons []string
+	for _, b := range backups {
+		reasons = append(reasons, b.Reason)
+	}
+	if want := []string{"install", "add la", "add ll"}; !reflect.DeepEqual(reasons, want) {
+		t.Fatalf("backups = %v, want %v", reasons, want)
+	}
+	if len(backups[0].Files) != 1 {
+		t.Errorf("the install backup holds %+v, want only the existing file", backups[0].Files)
+	}
+
+	// A shell config file is put back as it was, permissions included.
+	if err := restoreBackup(paths, backups[0]); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "export A=1\n" || info.Mode().Perm() != 0600 {
+		t.Errorf(".bashrc = %q with mode %v, want the backed up version", content, info.Mode().Perm())
+	}
+	if changes, err := restoreChanges(paths.StateDir, backups[0]); err != nil || len(changes) != 0 {
+		t.Errorf("restoring again would change %+v, %v", changes, err)
+	}
+
+	// Restoring the store goes through the store, so it regenerates the
+	// alias file and can be undone.
+	if err := restoreBackup(paths, backups[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := storeNames(t, paths.Store), []string{reloadAliasName, "ll"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names after restoring = %v, want %v", got, want)
+	}
+	script, err := os.ReadFile(paths.AliasFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isGeneratedFile(paths.AliasFile) || strings.Contains(string(script), "alias la=") {
+		t.Errorf("the alias file was not regenerated:\n%s", script)
+	}
+	if desc, err := undoChange(paths); err != nil || desc != "restore "+backups[1].ID {
+		t.Errorf("undo = %q, %v; want the restore undone", desc, err)
+	}
+	if got, want := storeNames(t, paths.Store), []string{reloadAliasName, "ll", "la"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names after undoing the restore = %v, want %v", got, want)
+	}
+
+	if _, err := readBackup(paths.StateDir, "nope"); err == nil {
+		t.Error("reading a missing backup succeeded")
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	stateDir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxBackups+2; i++ {
+		if err := backupFiles(stateDir, "change", []string{file}); err != nil {
+			t.Fatal(err)
+		}
+		// Backups are named after the time they were made.
+		time.Sleep(time.Millisecond)
+	}
+	backups, err := listBackups(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != maxBackups {
+		t.Errorf("%d backups were kept, want %d", len(backups), maxBackups)
+	}
+}
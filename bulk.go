@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bulkAction is a change that can be applied to several definitions at once,
+// recorded as a single change so that one undo reverts it.
+type bulkAction struct {
+	label string
+	// input, if not empty, is the label of the value the action asks for.
+	input string
+	// reason describes the change for the journal and the confirmation.
+	reason func(names, value string) string
+	apply  func(store *AliasStore, name, value string) error
+}
+
+var bulkActions = []bulkAction{
+	{
+		label:  "Delete",
+		reason: func(names, _ string) string { return "remove " + names },
+		apply: func(store *AliasStore, name, _ string) error {
+			return store.Remove(name)
+		},
+	},
+	{
+		label:  "Disable",
+		reason: func(names, _ string) string { return "disable " + names },
+		apply: func(store *AliasStore, name, _ string) error {
+			return store.SetDisabled(name, true)
+		},
+	},
+	{
+		label:  "Enable",
+		reason: func(names, _ string) string { return "enable " + names },
+		apply: func(store *AliasStore, name, _ string) error {
+			return store.SetDisabled(name, false)
+		},
+	},
+	{
+		label: "Move to group",
+		input: "Group (empty for none)",
+		reason: func(names, group string) string {
+			if group == "" {
+				return "take " + names + " out of their groups"
+			}
+			return "move " + names + " to group " + group
+		},
+		apply: func(store *AliasStore, name, group string) error {
+			alias, ok := store.Get(name)
+			if !ok {
+				return fmt.Errorf("%q is not defined", name)
+			}
+			alias.Group = group
+			return store.Update(name, alias)
+		},
+	},
+	{
+		label: "Add tags",
+		input: "Tags",
+		reason: func(names, tags string) string {
+			return "tag " + names + " with " + strings.Join(parseTags(tags), ", ")
+		},
+		apply: func(store *AliasStore, name, tags string) error {
+			alias, ok := store.Get(name)
+			if !ok {
+				return fmt.Errorf("%q is not defined", name)
+			}
+			alias.Tags = parseTags(strings.Join(append(alias.Tags, parseTags(tags)...), ","))
+			return store.Update(name, alias)
+		},
+	},
+}
+
+// applyBulkAction applies action with value to the definitions called names
+// as one change.
+func applyBulkAction(paths appPaths, action bulkAction, names []string, value string) error {
+	return editAliasStore(paths, action.reason(nameList(names), value), func(store *AliasStore) error {
+		for _, name := range names {
+			if err := action.apply(store, name, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// nameList joins names for a message, shortening long lists.
+func nameList(names []string) string {
+	const max = 5
+	if len(names) <= max {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:max], ", "), len(names)-max)
+}
+
+// exportAliases writes the definitions called names, as they are generated,
+// to path as a script for shell. It returns the definitions that shell cannot
+// run, which are left out.
+func exportAliases(storePath string, names []string, shell, path string) ([]string, error) {
+	store, err := loadAliasStore(storePath)
+	if err != nil {
+		return nil, err
+	}
+	var aliases []Alias
+	for _, alias := range store.EffectiveAliases() {
+		for _, name := range names {
+			if alias.Name == name {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	script, warnings, err := generateShellScript(shell, aliases, store.Config())
+	if err != nil {
+		return nil, err
+	}
+	return warnings, writeFileAtomic(path, []byte(script), 0644)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyBulkAction(t *testing.T) {
+	action := func(label string) bulkAction {
+		for _, a := range bulkActions {
+			if a.label == label {
+				return a
+			}
+		}
+		t.Fatalf("no bulk action %q", label)
+		return bulkAction{}
+	}
+	tests := []struct {
+		action string
+		names  []string
+		value  string
+		want   []string // the definitions afterwards, see describe
+		err    string
+	}{
+		{"Disable", []string{"a", "b"}, "", []string{"!a", "!b", "c#z,x"}, ""},
+		{"Move to group", []string{"a", "c"}, "work", []string{"a@work", "b", "c@work#z,x"}, ""},
+		// Tags are added once, after those already there.
+		{"Add tags", []string{"b", "c"}, "x, y", []string{"a", "b#x,y", "c#z,x,y"}, ""},
+		{"Delete", []string{"a", "c"}, "", []string{"b"}, ""},
+		// One missing definition leaves the others alone too.
+		{"Delete", []string{"a", "nope"}, "", []string{"a", "b", "c#z,x"}, `"nope" is not defined`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			paths := testPaths(t)
+			err := editAliasStore(paths, "set up", func(store *AliasStore) error {
+				store.Remove(reloadAliasName)
+				for _, alias := range []Alias{
+					{Name: "a", Type: "alias", Command: "x"},
+					{Name: "b", Type: "alias", Command: "y"},
+					{Name: "c", Type: "alias", Command: "z", Tags: []string{"z", "x"}},
+				} {
+					if err := store.Add(alias); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = applyBulkAction(paths, action(tt.action), tt.names, tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			store, err := loadAliasStore(paths.Store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, alias := range store.Aliases() {
+				got = append(got, describe(alias))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("definitions = %v, want %v", got, tt.want)
+			}
+
+			// The whole action is undone in one step.
+			if tt.err == "" {
+				if desc, err := undoChange(paths); err != nil || desc != action(tt.action).reason(nameList(tt.names), tt.value) {
+					t.Errorf("undo = %q, %v", desc, err)
+				}
+				if got := storeNames(t, paths.Store); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+					t.Errorf("after undo: %v", got)
+				}
+			}
+		})
+	}
+}
+
+// describe returns the name of alias, prefixed with "!" when it is disabled
+// and followed by "@group" and "#tags".
+func describe(alias Alias) string {
+	s := alias.Name
+	if alias.Disabled {
+		s = "!" + s
+	}
+	if alias.Group != "" {
+		s += "@" + alias.Group
+	}
+	if len(alias.Tags) > 0 {
+		s += "#" + strings.Join(alias.Tags, ",")
+	}
+	return s
+}
+
+func TestNameList(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b", "c", "d", "e"}, "a, b, c, d, e"},
+		{[]string{"a", "b", "c", "d", "e", "f", "g"}, "a, b, c, d, e and 2 more"},
+	}
+	for _, tt := range tests {
+		if got := nameList(tt.names); got != tt.want {
+			t.Errorf("nameList(%v) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestExportAliases(t *testing.T) {
+	paths := testPaths(t)
+	err := editAliasStore(paths, "set up", func(store *AliasStore) error {
+		for _, alias := range []Alias{
+			{Name: "ll", Type: "alias", Command: "ls -l"},
+			{Name: "G", Type: "global", Command: "| grep"},
+			{Name: "other", Type: "alias", Command: "true"},
+		} {
+			if err := store.Add(alias); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "export.fish")
+	skipped, err := exportAliases(paths.Store, []string{"ll", "G"}, "fish", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("skipped %v", skipped)
+	}
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"alias ll 'ls -l'\n", "abbr --add --position anywhere -- G '| grep'\n"} {
+		if !strings.Contains(string(script), want) {
+			t.Errorf("the export lacks %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(string(script), "other") {
+		t.Errorf("the export holds a definition that was not marked:\n%s", script)
+	}
+
+	skipped, err = exportAliases(paths.Store, []string{"ll", "G"}, "bash", path)
+	if err != nil || len(skipped) != 1 || !strings.HasPrefix(skipped[0], "global G") {
+		t.Errorf("exporting a global alias to bash skipped %v, %v", skipped, err)
+	}
+}
@@ -0,0 +1,733 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Exit codes of the non-interactive subcommands.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type cliCommand struct {
+	usage string
+	run   func(paths appPaths, args []string) error
+}
+
+var cliCommands map[string]cliCommand
+
+func init() {
+	cliCommands = map[string]cliCommand{
+		"list":      {"list [--format FORMAT] [--sort KEY] [--shell SHELL] [--group GROUP]", cmdList},
+		"export":    {"export [--shell SHELL] [--output FILE]", cmdExport},
+		"show":      {"show NAME", cmdShow},
+		"add":       {"add [--function|--global|--suffix] [--group GROUP] [--description TEXT] [--tags LIST] NAME COMMAND|-", cmdAdd},
+		"edit":      {"edit [--command COMMAND|-] [--group GROUP] [--description TEXT] [--tags LIST] NAME", cmdEdit},
+		"rename":    {"rename OLD NEW", cmdRename},
+		"rm":        {"rm NAME", cmdRemove},
+		"groups":    {"groups", cmdGroups},
+		"disable":   {"disable NAME...|--group GROUP", cmdDisable},
+		"enable":    {"enable NAME...|--group GROUP", cmdEnable},
+		"install":   {"install [--all] [FILE...]", cmdInstall},
+		"uninstall": {"uninstall [--archive] [--yes]", cmdUninstall},
+		"undo":      {"undo", cmdUndo},
+		"redo":      {"redo", cmdRedo},
+		"backups":   {"backups", cmdBackups},
+		"restore":   {"restore [--yes] ID", cmdRestore},
+		"history":   {"history on|off", cmdHistory},
+		"log":       {"log [-n COUNT]", cmdLog},
+		"diff":      {"diff [REV [REV]]", cmdDiff},
+	}
+}
+
+// errBadFlags wraps flag parsing errors, which the flag package has already
+// reported together with the usage.
+var errBadFlags = errors.New("invalid flags")
+
+// usageError is returned by subcommands that were called with bad arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// runCli runs the subcommand named by args[0] and returns the exit code.
+func runCli(paths appPaths, args []string) int {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printCliUsage(os.Stdout)
+		return exitOK
+	}
+
+	command, ok := cliCommands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "aliasman: unknown command %q\n\n", name)
+		printCliUsage(os.Stderr)
+		return exitUsage
+	}
+
+	err := command.run(paths, args[1:])
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "aliasman %s: %v\nusage: aliasman %s\n", name, err, command.usage)
+		return exitUsage
+	case errors.Is(err, errBadFlags):
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "aliasman %s: %v\n", name, err)
+		return exitError
+	}
+}
+
+func printCliUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: aliasman [--config FILE] [COMMAND]")
+	fmt.Fprintln(w, "\nWithout a command, the interactive interface is started.")
+	fmt.Fprintln(w, "--config uses FILE as the alias store instead of the default one.")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, name := range []string{"list", "export", "show", "add", "edit", "rename", "rm", "groups", "disable", "enable", "undo", "redo", "install", "uninstall", "backups", "restore", "history", "log", "diff"} {
+		fmt.Fprintf(w, "  aliasman %s\n", cliCommands[name].usage)
+	}
+}
+
+// parseGlobalFlags parses the flags that come before the command and returns
+// the value of --config and the remaining arguments.
+func parseGlobalFlags(args []string) (string, []string, error) {
+	fs := flag.NewFlagSet("aliasman", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {}
+	config := fs.String("config", "", "")
+	if err := fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	return *config, fs.Args(), nil
+}
+
+// parseFlags parses args with fs, allowing flags to appear between positional
+// arguments, and returns the positional arguments. Everything after "--" is
+// positional.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", errBadFlags, err)
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("aliasman "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: aliasman %s\n", cliCommands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// readCommandArg returns the command given on the command line, reading it
+// from standard input when it is "-".
+func readCommandArg(arg string) (string, error) {
+	if arg != "-" {
+		return arg, nil
+	}
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(content), "\n"), nil
+}
+
+func cmdList(paths appPaths, args []string) error {
+	fs := newFlagSet("list")
+	format := fs.String("format", "text", "output format: "+strings.Join(listFormats, ", "))
+	sortKey := fs.String("sort", "line", "sort by: "+strings.Join(listSortKeys, ", "))
+	shell := fs.String("shell", "", "only list definitions supported by this shell: "+strings.Join(shellTargetNames(), ", "))
+	group := fs.String("group", "", "only list the definitions in `GROUP`")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError("unexpected arguments")
+	}
+	if !slices.Contains(listFormats, *format) {
+		return usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+	target, ok := shellTargets[*shell]
+	if *shell != "" && !ok {
+		return usageError(fmt.Sprintf("unsupported shell %q", *shell))
+	}
+
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		return fmt.Errorf("error loading aliases and functions: %w", err)
+	}
+
+	aliases := store.EffectiveAliases()
+	if *group != "" {
+		aliases = slices.DeleteFunc(aliases, func(alias Alias) bool {
+			return alias.Group != *group
+		})
+	}
+	if *shell != "" {
+		config := store.Config()
+		aliases = slices.DeleteFunc(aliases, func(alias Alias) bool {
+			_, err := target.render(alias, config)
+			return err != nil
+		})
+	}
+	if err := sortAliases(aliases, *sortKey); err != nil {
+		return usageError(err.Error())
+	}
+	return writeAliasList(os.Stdout, *format, aliases)
+}
+
+func cmdExport(paths appPaths, args []string) error {
+	fs := newFlagSet("export")
+	shell := fs.String("shell", "bash", "shell to generate a script for: "+strings.Join(shellTargetNames(), ", "))
+	output := fs.String("output", "", "write the script to `FILE` instead of standard output")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError("unexpected arguments")
+	}
+	if _, ok := shellTargets[*shell]; !ok {
+		return usageError(fmt.Sprintf("unsupported shell %q", *shell))
+	}
+
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		return err
+	}
+	script, warnings, err := generateShellScript(*shell, store.EffectiveAliases(), store.Config())
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning: skipped", warning)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.WriteString(script)
+		return err
+	}
+	return os.WriteFile(*output, []byte(script), 0644)
+}
+
+func cmdShow(paths appPaths, args []string) error {
+	fs := newFlagSet("show")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("expected exactly one NAME")
+	}
+
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		return err
+	}
+	alias, ok := store.Get(positional[0])
+	if !ok {
+		return fmt.Errorf("%q is not defined", positional[0])
+	}
+
+	if alias.Description != "" {
+		fmt.Println("#", alias.Description)
+	}
+	if alias.Group != "" {
+		fmt.Println("# group:", alias.Group)
+	}
+	switch {
+	case alias.Disabled:
+		fmt.Println("# disabled")
+	case store.GroupDisabled(alias.Group):
+		fmt.Println("# disabled with its group")
+	}
+	if len(alias.Tags) > 0 {
+		fmt.Println("# tags:", strings.Join(alias.Tags, ", "))
+	}
+	if history := aliasHistory(alias); history != "" {
+		fmt.Println("#", history)
+	}
+	fmt.Print(formatAlias(alias))
+	return nil
+}
+
+func cmdAdd(paths appPaths, args []string) error {
+	fs := newFlagSet("add")
+	function := fs.Bool("function", false, "add a function instead of an alias")
+	global := fs.Bool("global", false, "add a zsh global alias, expanded anywhere on the command line")
+	suffix := fs.Bool("suffix", false, "add a zsh suffix alias, run for files ending in .NAME")
+	group := fs.String("group", "", "put the definition in `GROUP`")
+	description := fs.String("description", "", "what the alias or function is for")
+	tags := fs.String("tags", "", "tags, separated by commas")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	// The command is taken as one argument: joining several would lose the
+	// quoting the shell removed from them.
+	if len(positional) != 2 {
+		return usageError("expected NAME and COMMAND; quote the command as one argument, or pass - to read it from standard input")
+	}
+
+	alias := Alias{Name: positional[0], Type: "alias", Group: *group, Description: *description, Tags: parseTags(*tags)}
+	for aliasType, set := range map[string]bool{"function": *function, "global": *global, "suffix": *suffix} {
+		if !set {
+			continue
+		}
+		if alias.Type != "alias" {
+			return usageError("only one of --function, --global and --suffix can be given")
+		}
+		alias.Type = aliasType
+	}
+
+	alias.Command, err = readCommandArg(positional[1])
+	if err != nil {
+		return err
+	}
+
+	return editAliasStore(paths, "add "+alias.Name, func(store *AliasStore) error {
+		return store.Add(alias)
+	})
+}
+
+func cmdEdit(paths appPaths, args []string) error {
+	fs := newFlagSet("edit")
+	command := fs.String("command", "", "replace the command instead of opening $EDITOR (- reads standard input)")
+	group := fs.String("group", "", "move the definition to `GROUP` (\"\" takes it out of its group)")
+	description := fs.String("description", "", "replace the description")
+	tags := fs.String("tags", "", "replace the tags with this list, separated by commas")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("expected exactly one NAME")
+	}
+	name := positional[0]
+
+	// Without any of the flags the definition is edited in $EDITOR; an
+	// empty --description or --tags clears them.
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	if len(set) > 0 {
+		body := *command
+		if body != "" {
+			if body, err = readCommandArg(body); err != nil {
+				return err
+			}
+		}
+		return editAliasStore(paths, "edit "+name, func(store *AliasStore) error {
+			alias, ok := store.Get(name)
+			if !ok {
+				return fmt.Errorf("%q is not defined", name)
+			}
+			if body != "" {
+				alias.Command = body
+			}
+			if set["group"] {
+				alias.Group = *group
+			}
+			if set["description"] {
+				alias.Description = *description
+			}
+			if set["tags"] {
+				alias.Tags = parseTags(*tags)
+			}
+			return store.Update(name, alias)
+		})
+	}
+
+	// The editor runs without holding the store lock, so other aliasman
+	// processes are not kept waiting.
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		return err
+	}
+	alias, ok := store.Get(name)
+	if !ok {
+		return fmt.Errorf("%q is not defined", name)
+	}
+	edited, err := editInEditor(formatAlias(alias))
+	if err != nil {
+		return fmt.Errorf("error running editor: %w", err)
+	}
+	updated, err := parseDefinition(edited)
+	if err != nil {
+		return fmt.Errorf("edited definition is not valid: %w", err)
+	}
+	updated.Group, updated.Description, updated.Tags = alias.Group, alias.Description, alias.Tags
+	return editAliasStore(paths, "edit "+name, func(store *AliasStore) error {
+		return store.Update(name, updated)
+	})
+}
+
+func cmdRename(paths appPaths, args []string) error {
+	fs := newFlagSet("rename")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return usageError("expected OLD and NEW")
+	}
+
+	return editAliasStore(paths, fmt.Sprintf("rename %s to %s", positional[0], positional[1]), func(store *AliasStore) error {
+		alias, ok := store.Get(positional[0])
+		if !ok {
+			return fmt.Errorf("%q is not defined", positional[0])
+		}
+		alias.Name = positional[1]
+		return store.Update(positional[0], alias)
+	})
+}
+
+func cmdRemove(paths appPaths, args []string) error {
+	fs := newFlagSet("rm")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("expected exactly one NAME")
+	}
+
+	return editAliasStore(paths, "remove "+positional[0], func(store *AliasStore) error {
+		return store.Remove(positional[0])
+	})
+}
+
+func cmdGroups(paths appPaths, args []string) error {
+	fs := newFlagSet("groups")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError("unexpected arguments")
+	}
+
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		return err
+	}
+	for _, group := range groupAliases(store.Aliases()) {
+		name := group.Name
+		if name == "" {
+			name = "(no group)"
+		}
+		state := ""
+		if store.GroupDisabled(group.Name) {
+			state = "\tdisabled"
+		}
+		fmt.Printf("%s\t%d%s\n", name, len(group.Aliases), state)
+	}
+	return nil
+}
+
+func cmdDisable(paths appPaths, args []string) error {
+	return setDisabledCommand("disable", paths, args, true)
+}
+
+func cmdEnable(paths appPaths, args []string) error {
+	return setDisabledCommand("enable", paths, args, false)
+}
+
+func setDisabledCommand(name string, paths appPaths, args []string, disabled bool) error {
+	fs := newFlagSet(name)
+	group := fs.String("group", "", name+" a whole `GROUP`")
+	names, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *group != "" {
+		if len(names) > 0 {
+			return usageError("--group cannot be combined with NAME arguments")
+		}
+		return editAliasStore(paths, name+" group "+*group, func(store *AliasStore) error {
+			return store.SetGroupDisabled(*group, disabled)
+		})
+	}
+	if len(names) == 0 {
+		return usageError("expected at least one NAME")
+	}
+	return editAliasStore(paths, name+" "+strings.Join(names, ", "), func(store *AliasStore) error {
+		for _, n := range names {
+			if err := store.SetDisabled(n, disabled); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func cmdUndo(paths appPaths, args []string) error {
+	return stepJournalCommand("undo", "Undid", paths, args, undoChange)
+}
+
+func cmdRedo(paths appPaths, args []string) error {
+	return stepJournalCommand("redo", "Redid", paths, args, redoChange)
+}
+
+func stepJournalCommand(name, done string, paths appPaths, args []string, step func(appPaths) (string, error)) error {
+	fs := newFlagSet(name)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError("unexpected arguments")
+	}
+
+	description, err := step(paths)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", done, description)
+	return nil
+}
+
+func cmdInstall(paths appPaths, args []string) error {
+	fs := newFlagSet("install")
+	all := fs.Bool("all", false, "install into every known shell config file, creating missing ones")
+	configs, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	switch {
+	case *all && len(configs) > 0:
+		return usageError("--all cannot be combined with FILE arguments")
+	case *all && paths.relocated():
+		return usageError("--all cannot be used with --config or ALIASMAN_HOME; name the FILEs to install into")
+	case *all:
+		configs = shellConfigCandidates(homeDir)
+	case len(configs) == 0 && paths.relocated():
+		// An isolated instance never picks the user's own config files.
+		configs = recordedShellConfigs(paths.Store)
+		if len(configs) == 0 {
+			return usageError("with --config or ALIASMAN_HOME, name the FILEs to install into")
+		}
+	case len(configs) == 0:
+		configs = detectShellConfigs(homeDir)
+	}
+
+	for i, path := range configs {
+		if configs[i], err = filepath.Abs(path); err != nil {
+			return err
+		}
+	}
+	if err := installAliasman(paths, configs); err != nil {
+		return err
+	}
+	for _, path := range configs {
+		fmt.Println("Installed into", path)
+	}
+	return nil
+}
+
+func cmdUninstall(paths appPaths, args []string) error {
+	fs := newFlagSet("uninstall")
+	archive := fs.Bool("archive", false, "move the alias store aside instead of leaving it in place")
+	yes := fs.Bool("yes", false, "apply the changes without asking")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError("unexpected arguments")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	changes, err := planUninstall(paths, homeDir)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No shell config file contains the Aliasman managed block.")
+	}
+	for _, c := range changes {
+		fmt.Print(c.Diff())
+	}
+
+	if !*yes && (len(changes) > 0 || *archive) && !confirm("Apply these changes?") {
+		return errors.New("aborted")
+	}
+
+	archivePath, err := uninstallAliasman(paths, changes, *archive)
+	if err != nil {
+		return err
+	}
+	if archivePath != "" {
+		fmt.Println("Aliases archived to", archivePath)
+	}
+	return nil
+}
+
+func cmdBackups(paths appPaths, args []string) error {
+	fs := newFlagSet("backups")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError("unexpected arguments")
+	}
+
+	backups, err := listBackups(paths.StateDir)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups yet.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTIME\tREASON\tFILES")
+	for _, b := range backups {
+		var files []string
+		for _, f := range b.Files {
+			files = append(files, f.Path)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", b.ID, b.Time.Local().Format("2006-01-02 15:04:05"), b.Reason, strings.Join(files, ", "))
+	}
+	return tw.Flush()
+}
+
+func cmdRestore(paths appPaths, args []string) error {
+	fs := newFlagSet("restore")
+	yes := fs.Bool("yes", false, "restore without asking")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("expected exactly one backup ID (see aliasman backups)")
+	}
+
+	b, err := readBackup(paths.StateDir, positional[0])
+	if err != nil {
+		return err
+	}
+	changes, err := restoreChanges(paths.StateDir, b)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Printf("The files already match backup %s.\n", b.ID)
+		return nil
+	}
+	for _, c := range changes {
+		fmt.Print(c.Diff())
+	}
+
+	if !*yes && !confirm("Restore these files?") {
+		return errors.New("aborted")
+	}
+	return restoreBackup(paths, b)
+}
+
+func cmdHistory(paths appPaths, args []string) error {
+	fs := newFlagSet("history")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("expected on or off")
+	}
+
+	switch positional[0] {
+	case "on":
+		if err := enableGitHistory(paths); err != nil {
+			return err
+		}
+		fmt.Println("Every change is now committed to the git repository in", filepath.Dir(paths.Store))
+		return nil
+	case "off":
+		return disableGitHistory(paths)
+	default:
+		return usageError(fmt.Sprintf("expected on or off, not %q", positional[0]))
+	}
+}
+
+func cmdLog(paths appPaths, args []string) error {
+	fs := newFlagSet("log")
+	count := fs.Int("n", 0, "show only the last `COUNT` changes")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError("unexpected arguments")
+	}
+	return showGitLog(paths.Store, *count)
+}
+
+func cmdDiff(paths appPaths, args []string) error {
+	fs := newFlagSet("diff")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: aliasman %s\n", cliCommands["diff"].usage)
+		fmt.Fprintln(os.Stderr, "Without REV, shows the last change; with one, the changes since REV.")
+	}
+	revs, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(revs) > 2 {
+		return usageError("expected at most two revisions")
+	}
+	return showGitDiff(paths.Store, revs)
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	var answer string
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	// xLines returns lines of from to to x characters, one more on each line.
+	xLines := func(from, to int) string {
+		var b strings.Builder
+		for i := from; i <= to; i++ {
+			b.WriteString(strings.Repeat("x", i) + "\n")
+		}
+		return b.String()
+	}
+	tests := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"from nothing", "", "x\n", "--- f\n+++ f\n@@ -0,0 +1 @@\n+x\n"},
+		{"to nothing", "x\ny\n", "", "--- f\n+++ f\n@@ -1,2 +0,0 @@\n-x\n-y\n"},
+		{"line added", "a\nb\n", "a\nb\nc\n", "--- f\n+++ f\n@@ -1,2 +1,3 @@\n a\n b\n+c\n"},
+		{
+			"lines removed far apart",
+			xLines(1, 12), xLines(2, 11),
+			"--- f\n+++ f\n" +
+				"@@ -1,4 +1,3 @@\n-x\n xx\n xxx\n xxxx\n" +
+				"@@ -9,4 +8,3 @@\n " + strings.Repeat("x", 9) + "\n " + strings.Repeat("x", 10) + "\n " + strings.Repeat("x", 11) + "\n-" + strings.Repeat("x", 12) + "\n",
+		},
+		{
+			"nearby changes share a hunk",
+			xLines(1, 6), xLines(2, 5),
+			"--- f\n+++ f\n@@ -1,6 +1,4 @@\n-x\n xx\n xxx\n xxxx\n xxxxx\n-xxxxxx\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := unifiedDiff("f", tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: unifiedDiff =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
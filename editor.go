@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editorCommand returns the command line of the user's preferred editor.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// editInEditor opens text in the user's editor and returns what was saved.
+// It must run while the terminal is not in use by the TUI.
+func editInEditor(text string) (string, error) {
+	f, err := os.CreateTemp("", "aliasman-*.sh")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// editorTemplate returns the text a definition is edited as, using a
+// placeholder name for definitions that do not have one yet.
+func editorTemplate(alias Alias) string {
+	if alias.Name == "" {
+		alias.Name = "my_" + alias.Type
+	}
+	return formatAlias(alias)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// fishSyntaxRules catch the bash constructs that fish rejects or reads
+// differently.
+var fishSyntaxRules = []syntaxRule{
+	{regexp.MustCompile(`\$\{`), "${...} expansion"},
+	{regexp.MustCompile(`\$\(\(`), "arithmetic expansion"},
+	{regexp.MustCompile(`\$[0-9@#*?]`), "positional parameters (use $argv)"},
+	{regexp.MustCompile("`"), "backquoted command substitution"},
+	{regexp.MustCompile(`\[\[`), "[[ ... ]] tests"},
+	{regexp.MustCompile(`<<`), "here-documents"},
+	{regexp.MustCompile(`(?m)(^|[;&|])\s*(then|fi|do|done|esac)\b`), "if/for/while/case blocks"},
+	{regexp.MustCompile(`(?m)(^|[;&|]\s*)(local|declare|typeset)\s`), "local variables"},
+	{regexp.MustCompile(`(?m)(^|[;&|])\s*[A-Za-z_][A-Za-z0-9_]*=\S*\s*($|[;&|])`), "variable assignments"},
+}
+
+// fishConfigPath returns where the fish script is generated. Fish sources
+// every file in conf.d on startup.
+func fishConfigPath(homeDir string) string {
+	return filepath.Join(configHome(homeDir), "fish", "conf.d", "aliasman.fish")
+}
+
+// renderFish renders aliases as fish aliases, or abbreviations when the
+// fish_abbr setting is on, and functions as fish functions.
+func renderFish(alias Alias, config Config) (string, error) {
+	if alias.Name == reloadAliasName {
+		// The reload alias sources the bash alias file; fish has to source
+		// its own script instead.
+		for _, path := range config.RCFiles {
+			if generatedShell(path) == "fish" {
+				alias.Command = "source " + shellQuote(path)
+			}
+		}
+	}
+
+	if problems := unsupportedSyntax(alias.Command, fishSyntaxRules); len(problems) > 0 {
+		return "", fmt.Errorf("uses bash syntax fish does not support: %s", strings.Join(problems, ", "))
+	}
+
+	switch alias.Type {
+	case "alias":
+		if config.FishAbbr {
+			return fmt.Sprintf("abbr --add -- %s %s\n", alias.Name, fishQuote(alias.Command)), nil
+		}
+		return fmt.Sprintf("alias %s %s\n", alias.Name, fishQuote(alias.Command)), nil
+	case "global":
+		// Fish has no global aliases, but abbreviations can expand anywhere.
+		return fmt.Sprintf("abbr --add --position anywhere -- %s %s\n", alias.Name, fishQuote(alias.Command)), nil
+	case "function":
+		var b strings.Builder
+		fmt.Fprintf(&b, "function %s\n", alias.Name)
+		for _, line := range strings.Split(alias.Command, "\n") {
+			if strings.TrimSpace(line) != "" {
+				b.WriteString("    " + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("end\n")
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("not supported by fish")
+}
+
+// fishQuote single-quotes s for fish, where backslashes and single quotes are
+// escaped with a backslash inside single quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
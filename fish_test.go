@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFish(t *testing.T) {
+	const script = "/home/u/.config/fish/conf.d/aliasman.fish"
+	tests := []struct {
+		name   string
+		alias  Alias
+		config Config
+		want   string
+		err    string // a part of the error, if rendering fails
+	}{
+		{
+			name:  "alias",
+			alias: Alias{Name: "ll", Type: "alias", Command: "ls -l"},
+			want:  "alias ll 'ls -l'\n",
+		},
+		{
+			name:  "quotes and backslashes",
+			alias: Alias{Name: "q", Type: "alias", Command: `echo 'a\b'`},
+			want:  `alias q 'echo \'a\\b\''` + "\n",
+		},
+		{
+			name:   "abbreviation",
+			alias:  Alias{Name: "gs", Type: "alias", Command: "git status"},
+			config: Config{FishAbbr: true},
+			want:   "abbr --add -- gs 'git status'\n",
+		},
+		{
+			name:  "global alias",
+			alias: Alias{Name: "G", Type: "global", Command: "| grep"},
+			want:  "abbr --add --position anywhere -- G '| grep'\n",
+		},
+		{
+			name:  "function",
+			alias: Alias{Name: "greet", Type: "function", Command: "echo hi\n\necho there"},
+			want:  "function greet\n    echo hi\n\n    echo there\nend\n",
+		},
+		{
+			name:   "reload alias",
+			alias:  Alias{Name: reloadAliasName, Type: "alias", Command: "source '/home/u/aliases.sh'"},
+			config: Config{RCFiles: []string{"/home/u/.bashrc", script}},
+			want:   "alias " + reloadAliasName + " 'source \\'" + script + "\\''\n",
+		},
+		{
+			name:  "suffix alias",
+			alias: Alias{Name: "txt", Type: "suffix", Command: "less"},
+			err:   "not supported by fish",
+		},
+		{
+			name:  "bash syntax",
+			alias: Alias{Name: "f", Type: "function", Command: "echo ${1:-x} $(( 1 + 2 ))"},
+			err:   "${...} expansion, arithmetic expansion",
+		},
+		{
+			name:  "assignment",
+			alias: Alias{Name: "f", Type: "function", Command: "x=1; echo $x"},
+			err:   "variable assignments",
+		},
+	}
+	for _, tt := range tests {
+		got, err := renderFish(tt.alias, tt.config)
+		switch {
+		case tt.err != "":
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: renderFish = %q, %v; want an error containing %q", tt.name, got, err, tt.err)
+			}
+		case err != nil:
+			t.Errorf("%s: renderFish: %v", tt.name, err)
+		case got != tt.want:
+			t.Errorf("%s: renderFish = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
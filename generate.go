@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// shellTarget renders definitions for one shell.
+type shellTarget struct {
+	comment string // line comment prefix
+	// render returns alias in the shell's syntax, or an error saying why the
+	// shell cannot run it.
+	render func(alias Alias, config Config) (string, error)
+}
+
+var shellTargets = map[string]shellTarget{
+	"bash":    {comment: "#", render: renderBash},
+	"fish":    {comment: "#", render: renderFish},
+	"nushell": {comment: "#", render: renderNushell},
+	"sh":      {comment: "#", render: renderPosix},
+	"zsh":     {comment: "#", render: renderZsh},
+}
+
+func shellTargetNames() []string {
+	names := make([]string, 0, len(shellTargets))
+	for name := range shellTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// generateShellScript renders aliases for shell. Definitions the shell cannot
+// run are left out with a comment in the script, and are reported in the
+// returned warnings.
+func generateShellScript(shell string, aliases []Alias, config Config) (string, []string, error) {
+	target, ok := shellTargets[shell]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported shell %q (expected one of %s)", shell, strings.Join(shellTargetNames(), ", "))
+	}
+	script, warnings := renderScript(target, aliases, config)
+	return script, warnings, nil
+}
+
+func renderScript(target shellTarget, aliases []Alias, config Config) (string, []string) {
+	script, _, warnings := renderScriptLines(target, aliases, config)
+	return script, warnings
+}
+
+// aliasFileLines returns the line of the alias file on which each of aliases,
+// by name, is generated.
+func aliasFileLines(aliases []Alias, config Config) map[string]int {
+	_, lines, _ := renderScriptLines(aliasFileTarget, aliases, config)
+	return lines
+}
+
+// renderScriptLines is renderScript that also returns the line each
+// definition starts on.
+func renderScriptLines(target shellTarget, aliases []Alias, config Config) (string, map[string]int, []string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s Do not edit: it is rewritten whenever your aliases change.\n\n", target.comment, generatedMarker)
+
+	var warnings []string
+	lines := make(map[string]int, len(aliases))
+	for _, group := range groupAliases(aliases) {
+		if group.Name != "" {
+			fmt.Fprintf(&b, "\n%s group: %s\n", target.comment, group.Name)
+		}
+		for _, alias := range group.Aliases {
+			lines[alias.Name] = strings.Count(b.String(), "\n") + 1
+			if alias.Disabled {
+				fmt.Fprintf(&b, "%s disabled %s %s\n", target.comment, alias.Type, alias.Name)
+				continue
+			}
+			text, err := target.render(alias, config)
+			if err != nil {
+				warning := fmt.Sprintf("%s %s: %v", alias.Type, alias.Name, err)
+				warnings = append(warnings, warning)
+				fmt.Fprintf(&b, "%s skipped %s\n", target.comment, warning)
+				continue
+			}
+			b.WriteString(text)
+		}
+	}
+	return b.String(), lines, warnings
+}
+
+// aliasFileTarget renders the alias file, which both bash and zsh source, so
+// zsh-only definitions are kept behind zshGuard.
+var aliasFileTarget = shellTarget{comment: "#", render: func(alias Alias, _ Config) (string, error) {
+	return formatAlias(alias), nil
+}}
+
+// generatedMarker starts the first line of every file aliasman generates, so
+// they can be recognized before being overwritten or removed.
+const generatedMarker = "Generated by aliasman."
+
+func isGeneratedFile(path string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(strings.SplitN(string(content), "\n", 2)[0], generatedMarker)
+}
+
+// generatedFile is a file aliasman generates and the script it should hold.
+type generatedFile struct {
+	path   string
+	script string
+}
+
+// renderGeneratedFiles renders the alias file and the scripts of the
+// installed shells that do not read the alias file directly, without writing
+// anything. It fails if any of them could not be written over, so that a
+// change is not saved when its files cannot be generated.
+func renderGeneratedFiles(aliases []Alias, config Config) ([]generatedFile, error) {
+	var files []generatedFile
+	if config.AliasFile != "" {
+		script, _ := renderScript(aliasFileTarget, aliases, config)
+		files = append(files, generatedFile{config.AliasFile, script})
+	}
+	for _, path := range config.RCFiles {
+		shell := generatedShell(path)
+		if shell == "" {
+			continue
+		}
+		script, _, err := generateShellScript(shell, aliases, config)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, generatedFile{path, script})
+	}
+	for _, file := range files {
+		if fileExists(file.path) && !isGeneratedFile(file.path) {
+			return nil, fmt.Errorf("refusing to overwrite %s, which was not generated by aliasman", file.path)
+		}
+	}
+	return files, nil
+}
+
+// writeGeneratedFiles writes files rendered by renderGeneratedFiles.
+func writeGeneratedFiles(files []generatedFile) error {
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(file.path, []byte(file.script), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatedShell returns the shell of a generated script, or "" for a shell
+// config file that sources the alias file.
+func generatedShell(path string) string {
+	switch filepath.Ext(path) {
+	case ".fish":
+		return "fish"
+	case ".nu":
+		return "nushell"
+	}
+	return ""
+}
+
+func renderBash(alias Alias, _ Config) (string, error) {
+	if _, ok := zshAliasFlags[alias.Type]; ok {
+		return "", fmt.Errorf("%s aliases are only supported by zsh", alias.Type)
+	}
+	return formatAlias(alias), nil
+}
+
+// syntaxRule flags a construct that some shells do not understand.
+type syntaxRule struct {
+	pattern *regexp.Regexp
+	desc    string
+}
+
+// unsupportedSyntax returns the descriptions of the rules command breaks.
+func unsupportedSyntax(command string, rules []syntaxRule) []string {
+	var found []string
+	for _, rule := range rules {
+		if rule.pattern.MatchString(command) && !slices.Contains(found, rule.desc) {
+			found = append(found, rule.desc)
+		}
+	}
+	return found
+}
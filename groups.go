@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Groups organize the aliases: every definition belongs to at most one, and a
+// group exists as long as some definition belongs to it.
+
+var groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func validateGroup(name string) error {
+	if name != "" && !groupNamePattern.MatchString(name) {
+		return fmt.Errorf("%q is not a valid group name", name)
+	}
+	return nil
+}
+
+// aliasGroup is a group and its definitions; the one named "" holds the
+// definitions that are not in any group.
+type aliasGroup struct {
+	Name    string
+	Aliases []Alias
+}
+
+// groupAliases splits aliases by group, keeping their order within each. The
+// ungrouped definitions come first, then the groups in the order they first
+// appear.
+func groupAliases(aliases []Alias) []aliasGroup {
+	groups := []aliasGroup{{}}
+	index := map[string]int{"": 0}
+	for _, alias := range aliases {
+		i, ok := index[alias.Group]
+		if !ok {
+			i = len(groups)
+			index[alias.Group] = i
+			groups = append(groups, aliasGroup{Name: alias.Group})
+		}
+		groups[i].Aliases = append(groups[i].Aliases, alias)
+	}
+	if len(groups[0].Aliases) == 0 {
+		groups = groups[1:]
+	}
+	return groups
+}
+
+// groupNames returns the names of the groups in aliases, in the order of
+// groupAliases, leaving out the ungrouped definitions.
+func groupNames(aliases []Alias) []string {
+	var names []string
+	for _, group := range groupAliases(aliases) {
+		if group.Name != "" {
+			names = append(names, group.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// The git history mode keeps the store in a git repository, the directory
+// holding the store or a repository it is already part of, and commits every
+// change to it. Only the store file is ever added or committed.
+
+// gitCommand returns a git command run in dir.
+func gitCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	return cmd
+}
+
+// runGit runs git in dir and returns its output, or an error including it.
+func runGit(dir string, args ...string) (string, error) {
+	output, err := gitCommand(dir, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// enableGitHistory turns on the git history mode, creating the repository if
+// the store is not in one yet. The store is committed as it is.
+func enableGitHistory(paths appPaths) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed")
+	}
+	if _, err := loadAliasStore(paths.Store); err != nil {
+		return err
+	}
+	dir, name := filepath.Split(paths.Store)
+	if _, err := runGit(dir, "rev-parse", "--git-dir"); err != nil {
+		if _, err := runGit(dir, "init", "--quiet"); err != nil {
+			return err
+		}
+		// The lock file and, with ALIASMAN_HOME, the generated script and
+		// state directory live next to the store; only the store is tracked.
+		exclude := filepath.Join(dir, ".git", "info", "exclude")
+		if err := os.MkdirAll(filepath.Dir(exclude), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(exclude, []byte("/*\n!/"+name+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return editAliasStore(paths, "enable git history", func(store *AliasStore) error {
+		config := store.Config()
+		config.GitHistory = true
+		return store.SetConfig(config)
+	})
+}
+
+func disableGitHistory(paths appPaths) error {
+	return editAliasStore(paths, "disable git history", func(store *AliasStore) error {
+		config := store.Config()
+		config.GitHistory = false
+		return store.SetConfig(config)
+	})
+}
+
+// commitStore commits the store file with message, leaving anything else
+// that is staged in the repository alone.
+func commitStore(storePath, message string) error {
+	dir, name := filepath.Split(storePath)
+	if _, err := runGit(dir, "add", "--", name); err != nil {
+		return err
+	}
+	if _, err := runGit(dir, "diff", "--cached", "--quiet", "--", name); err == nil {
+		// The store is already committed as it is, as when undoing a change
+		// whose commit failed.
+		return nil
+	}
+
+	args := []string{"commit", "--quiet", "--no-verify", "--message", "aliasman: " + message}
+	if email, _ := runGit(dir, "config", "user.email"); email == "" {
+		// Without an identity git refuses to commit.
+		args = append([]string{"-c", "user.name=aliasman", "-c", "user.email=aliasman@localhost"}, args...)
+	}
+	_, err := runGit(dir, append(args, "--", name)...)
+	return err
+}
+
+// checkGitRepository returns an error saying how to turn on git history if
+// the store at storePath is not in a git repository.
+func checkGitRepository(storePath string) error {
+	if _, err := runGit(filepath.Dir(storePath), "rev-parse", "--git-dir"); err != nil {
+		return fmt.Errorf("the aliases are not in a git repository; turn git history on with \"aliasman history on\"")
+	}
+	return nil
+}
+
+// showGitLog prints the history of the store, newest first.
+func showGitLog(storePath string, limit int) error {
+	if err := checkGitRepository(storePath); err != nil {
+		return err
+	}
+	dir, name := filepath.Split(storePath)
+	args := []string{"log", "--date=format:%Y-%m-%d %H:%M:%S", "--format=%h  %ad  %s"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	return runGitTo(dir, append(args, "--", name)...)
+}
+
+// showGitDiff prints the changes to the store between two revisions. With no
+// revisions it shows the last change, and with one the changes since then.
+func showGitDiff(storePath string, revs []string) error {
+	if err := checkGitRepository(storePath); err != nil {
+		return err
+	}
+	dir, name := filepath.Split(storePath)
+	switch len(revs) {
+	case 0:
+		// The last commit that changed the store, which need not be HEAD in
+		// a dotfiles repository. git log -p also works for the first commit,
+		// which has no parent.
+		return runGitTo(dir, "log", "-1", "-p", "--format=%h  %s", "--", name)
+	case 1:
+		revs = append(revs, "HEAD")
+	}
+	// The revisions come from the user and could be taken for options, such
+	// as --output, so they are resolved to commits first.
+	commits := make([]string, len(revs))
+	for i, rev := range revs {
+		commit, err := runGit(dir, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+		if err != nil {
+			return fmt.Errorf("%q is not a commit", rev)
+		}
+		commits[i] = commit
+	}
+	return runGitTo(dir, "diff", commits[0], commits[1], "--", name)
+}
+
+// runGitTo runs git in dir with its output going to the terminal.
+func runGitTo(dir string, args ...string) error {
+	cmd := gitCommand(dir, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
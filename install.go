@@ -0,0 +1,300 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// shellConfigNames are the shell config files aliasman knows about, relative
+// to the home directory.
+var shellConfigNames = []string{".bashrc", ".zshrc", ".bash_profile"}
+
+// loginShell returns the name of the user's login shell, such as "zsh", taken
+// from $SHELL or, failing that, from /etc/passwd.
+func loginShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	content, err := os.ReadFile("/etc/passwd")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) >= 7 && fields[0] == u.Username {
+			return filepath.Base(fields[6])
+		}
+	}
+	return ""
+}
+
+// shellConfigFor returns the config file a shell reads in interactive
+// sessions, or "" for shells aliasman cannot install into.
+func shellConfigFor(shell, homeDir string) string {
+	switch shell {
+	case "bash":
+		// macOS only reads .bash_profile, so prefer it when there is no .bashrc.
+		bashrc := filepath.Join(homeDir, ".bashrc")
+		profile := filepath.Join(homeDir, ".bash_profile")
+		if !fileExists(bashrc) && fileExists(profile) {
+			return profile
+		}
+		return bashrc
+	case "zsh":
+		if zdotdir := os.Getenv("ZDOTDIR"); zdotdir != "" {
+			return filepath.Join(zdotdir, ".zshrc")
+		}
+		return filepath.Join(homeDir, ".zshrc")
+	case "fish":
+		return fishConfigPath(homeDir)
+	case "nu":
+		return nushellConfigPath(homeDir)
+	}
+	return ""
+}
+
+// shellConfigured reports whether the user has set up the shell whose config
+// file is at path.
+func shellConfigured(path string) bool {
+	if generatedShell(path) != "" {
+		// ~/.config/fish/conf.d/aliasman.fish -> ~/.config/fish
+		return fileExists(filepath.Dir(filepath.Dir(path)))
+	}
+	return fileExists(path)
+}
+
+// detectShellConfigs returns the config files to install into: the login
+// shell's, which may not exist yet, followed by those of the other shells
+// that are already configured.
+func detectShellConfigs(homeDir string) []string {
+	var paths []string
+	if path := shellConfigFor(loginShell(), homeDir); path != "" {
+		paths = append(paths, path)
+	}
+	for _, shell := range []string{"bash", "zsh", "fish", "nu"} {
+		path := shellConfigFor(shell, homeDir)
+		if shellConfigured(path) && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// shellConfigCandidates returns every config file that can be installed into,
+// whether or not it exists yet.
+func shellConfigCandidates(homeDir string) []string {
+	paths := detectShellConfigs(homeDir)
+	for _, name := range shellConfigNames {
+		if path := filepath.Join(homeDir, name); !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range []string{fishConfigPath(homeDir), nushellConfigPath(homeDir)} {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// installCandidates returns the config files the installation dialog offers:
+// every known one, or for a relocated instance only those it recorded.
+func installCandidates(paths appPaths, homeDir string) []string {
+	if paths.relocated() {
+		return recordedShellConfigs(paths.Store)
+	}
+	return shellConfigCandidates(homeDir)
+}
+
+// recordedShellConfigs returns the config files installAliasman patched, as
+// recorded in the store.
+func recordedShellConfigs(storePath string) []string {
+	store, err := loadAliasStore(storePath)
+	if err != nil {
+		return nil
+	}
+	return store.Config().RCFiles
+}
+
+// isAliasmanInstalled reports whether the alias file exists and every one of
+// the shell configs sources it from within the managed block.
+func isAliasmanInstalled(storePath string, shellConfigPaths []string) bool {
+	store, err := loadAliasStore(storePath)
+	if err != nil || !fileExists(store.Config().AliasFile) || len(shellConfigPaths) == 0 {
+		return false
+	}
+	for _, path := range shellConfigPaths {
+		if !isInstalledIn(storePath, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// isInstalledIn reports whether the shell config sources the alias file from
+// within the managed block or, for shells that cannot source it, whether the
+// generated script is in place.
+func isInstalledIn(storePath, shellConfigPath string) bool {
+	store, err := loadAliasStore(storePath)
+	if err != nil {
+		return false
+	}
+	config := store.Config()
+	if generatedShell(shellConfigPath) != "" {
+		return isGeneratedFile(shellConfigPath) && slices.Contains(config.RCFiles, shellConfigPath)
+	}
+
+	content, err := os.ReadFile(shellConfigPath)
+	if err != nil {
+		return false
+	}
+
+	start, end, ok := findManagedBlock(string(content))
+	return ok && config.AliasFile != "" && blockSources(string(content)[start:end], config.AliasFile)
+}
+
+// installAliasman creates the store unless it already exists and makes each
+// of the shell configs source the alias file generated from it, creating
+// them if needed. Running it again is harmless: an existing managed block is
+// rewritten in place rather than added a second time. The patched files are
+// recorded in the store so that uninstalling can find them; recording a shell
+// that cannot source the alias file, such as fish, makes saving the store
+// generate its script. Shell config files are backed up before they are
+// changed.
+func installAliasman(paths appPaths, shellConfigPaths []string) error {
+	if err := createStore(paths.Store, paths.AliasFile); err != nil {
+		return fmt.Errorf("error creating alias store: %w", err)
+	}
+	if len(shellConfigPaths) == 0 {
+		return errors.New("no shell config file found")
+	}
+
+	// Installing changes files outside the store, so it is backed up but not
+	// recorded in the undo journal.
+	return changeStore(paths, "install", func(store *AliasStore) error {
+		config := store.Config()
+		if config.AliasFile == "" {
+			config.AliasFile = paths.AliasFile
+		}
+		for _, path := range shellConfigPaths {
+			if err := installInto(paths.StateDir, config.AliasFile, path); err != nil {
+				return err
+			}
+			if !slices.Contains(config.RCFiles, path) {
+				config.RCFiles = append(config.RCFiles, path)
+			}
+		}
+		return store.SetConfig(config)
+	}, notJournaled)
+}
+
+func installInto(stateDir, aliasFilePath, shellConfigPath string) error {
+	if generatedShell(shellConfigPath) != "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(shellConfigPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error reading shell config file: %w", err)
+	}
+
+	updated := withManagedBlock(string(content), aliasFilePath)
+	if updated == string(content) {
+		return nil
+	}
+	if err := backupFiles(stateDir, "install into "+shellConfigPath, []string{shellConfigPath}); err != nil {
+		return fmt.Errorf("error backing up shell config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(shellConfigPath), 0755); err != nil {
+		return fmt.Errorf("error creating shell config directory: %w", err)
+	}
+	if err := writeFileAtomic(shellConfigPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("error writing to shell config file: %w", err)
+	}
+	return nil
+}
+
+// reloadAliasName is the alias created on installation to re-source the
+// aliases in a running shell.
+const reloadAliasName = "aliasman-reload"
+
+// managedBlock returns the lines aliasman adds to a shell config file.
+func managedBlock(aliasFilePath string) string {
+	return fmt.Sprintf("%s\nsource %s\n%s\n", tagStart, shellQuote(aliasFilePath), tagEnd)
+}
+
+// findManagedBlock returns the byte range of the managed block in content,
+// from the start of the tagStart line to the end of the tagEnd line.
+func findManagedBlock(content string) (int, int, bool) {
+	offset := 0
+	start := -1
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case tagStart:
+			start = offset
+		case tagEnd:
+			if start >= 0 {
+				return start, offset + len(line), true
+			}
+		}
+		offset += len(line)
+	}
+	return 0, 0, false
+}
+
+// blockSources reports whether a managed block sources aliasFilePath.
+func blockSources(block, aliasFilePath string) bool {
+	for _, line := range strings.Split(block, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 || (fields[0] != "source" && fields[0] != ".") {
+			continue
+		}
+		path := strings.TrimSpace(fields[1])
+		if path == aliasFilePath || path == shellQuote(aliasFilePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// withManagedBlock returns content with its managed block replaced by one
+// sourcing aliasFilePath, or with such a block appended if it has none.
+func withManagedBlock(content, aliasFilePath string) string {
+	block := managedBlock(aliasFilePath)
+	if start, end, ok := findManagedBlock(content); ok {
+		return content[:start] + block + content[end:]
+	}
+
+	if content == "" {
+		return block
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + "\n" + block
+}
+
+// fileMode returns the permissions of the file at path, or def if it does
+// not exist.
+func fileMode(path string, def os.FileMode) os.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+	return def
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
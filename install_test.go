@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithManagedBlock(t *testing.T) {
+	const path = "/home/u/.aliasman_aliases"
+	block := tagStart + "\nsource '" + path + "'\n" + tagEnd + "\n"
+	tests := []struct {
+		name, content, want string
+	}{
+		{"empty file", "", block},
+		{"appended after a blank line", "export A=1\n", "export A=1\n\n" + block},
+		{"missing final newline", "export A=1", "export A=1\n\n" + block},
+		{"already installed", "export A=1\n\n" + block, "export A=1\n\n" + block},
+		{
+			"old block replaced in place",
+			"a\n" + tagStart + "\nsource ~/old\n" + tagEnd + "\nb\n",
+			"a\n" + block + "b\n",
+		},
+		{
+			"indented tags",
+			"a\n  " + tagStart + "\n  source ~/old\n  " + tagEnd + "\n",
+			"a\n" + block,
+		},
+		{"start tag alone is no block", tagStart + "\n", tagStart + "\n\n" + block},
+	}
+	for _, tt := range tests {
+		if got := withManagedBlock(tt.content, path); got != tt.want {
+			t.Errorf("%s: withManagedBlock(%q) = %q, want %q", tt.name, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestBlockSources(t *testing.T) {
+	const path = "/home/u/my aliases"
+	tests := []struct {
+		block string
+		want  bool
+	}{
+		{managedBlock(path), true},
+		{tagStart + "\n. '/home/u/my aliases'\n" + tagEnd + "\n", true},
+		{tagStart + "\nsource /home/u/other\n" + tagEnd + "\n", false},
+		{tagStart + "\n# source '/home/u/my aliases'\n" + tagEnd + "\n", false},
+	}
+	for _, tt := range tests {
+		if got := blockSources(tt.block, path); got != tt.want {
+			t.Errorf("blockSources(%q) = %v, want %v", tt.block, got, tt.want)
+		}
+	}
+}
+
+func TestInstallIntoIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	rc := filepath.Join(dir, ".bashrc")
+	if err := os.WriteFile(rc, []byte("export A=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	aliasFile := filepath.Join(dir, "aliases.sh")
+	for i := 0; i < 2; i++ {
+		if err := installInto(filepath.Join(dir, "state"), aliasFile, rc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "export A=1\n\n" + managedBlock(aliasFile); string(content) != want {
+		t.Errorf(".bashrc = %q, want %q", content, want)
+	}
+	info, err := os.Stat(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("the permissions of .bashrc changed to %v", info.Mode().Perm())
+	}
+}
+
+func TestDetectShellConfigs(t *testing.T) {
+	tests := []struct {
+		name  string
+		shell string
+		files []string // created in the home directory
+		want  []string
+	}{
+		{"login shell without a config file", "/bin/zsh", nil, []string{".zshrc"}},
+		{"other shells already in use", "/bin/bash", []string{".zshrc", ".config/fish/config.fish"},
+			[]string{".bashrc", ".zshrc", ".config/fish/conf.d/aliasman.fish"}},
+		{"bash_profile without bashrc", "/usr/local/bin/bash", []string{".bash_profile"}, []string{".bash_profile"}},
+		{"bashrc preferred", "/bin/bash", []string{".bashrc", ".bash_profile"}, []string{".bashrc"}},
+		{"nushell", "/usr/bin/nu", []string{".bashrc"}, []string{".config/nushell/autoload/aliasman.nu", ".bashrc"}},
+		{"unknown login shell", "/bin/tcsh", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("SHELL", tt.shell)
+			t.Setenv("ZDOTDIR", "")
+			t.Setenv("XDG_CONFIG_HOME", "")
+			for _, name := range tt.files {
+				path := filepath.Join(home, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var want []string
+			for _, name := range tt.want {
+				want = append(want, filepath.Join(home, name))
+			}
+			if got := detectShellConfigs(home); !reflect.DeepEqual(got, want) {
+				t.Errorf("detectShellConfigs = %v, want %v", got, want)
+			}
+		})
+	}
+}
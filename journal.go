@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxJournal is how many changes can be undone.
+const maxJournal = 100
+
+// journal records the changes made to the store so they can be undone and
+// redone, within a TUI session as well as across runs.
+type journal struct {
+	Entries []journalEntry `json:"entries"`
+	// Position is the number of entries in effect; the ones after it were
+	// undone and can be redone.
+	Position int `json:"position"`
+}
+
+// journalEntry is one change, kept as the whole store before and after it.
+type journalEntry struct {
+	Time        time.Time       `json:"time"`
+	Description string          `json:"description"`
+	Before      json.RawMessage `json:"before"`
+	After       json.RawMessage `json:"after"`
+}
+
+func journalPath(stateDir string) string {
+	return filepath.Join(stateDir, "journal.json")
+}
+
+func loadJournal(stateDir string) (journal, error) {
+	var j journal
+	content, err := os.ReadFile(journalPath(stateDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return j, err
+	}
+	if err := json.Unmarshal(content, &j); err != nil {
+		return j, fmt.Errorf("%s: %w", journalPath(stateDir), err)
+	}
+	return j, nil
+}
+
+func saveJournal(stateDir string, j journal) error {
+	content, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(journalPath(stateDir), content, 0600)
+}
+
+// recordChange adds a change to the journal, dropping the changes that were
+// undone since they can no longer be redone.
+func recordChange(stateDir, description string, before, after []byte) error {
+	j, err := loadJournal(stateDir)
+	if err != nil {
+		return err
+	}
+	j.Entries = append(j.Entries[:j.Position], journalEntry{
+		Time:        time.Now(),
+		Description: description,
+		Before:      before,
+		After:       after,
+	})
+	if len(j.Entries) > maxJournal {
+		j.Entries = j.Entries[len(j.Entries)-maxJournal:]
+	}
+	j.Position = len(j.Entries)
+	return saveJournal(stateDir, j)
+}
+
+// notJournaled is passed to changeStore for changes that also touch files
+// outside the store, such as installing, which the journal cannot undo.
+func notJournaled(before, after []byte) error {
+	return nil
+}
+
+// undoChange reverts the last change in effect and returns its description.
+func undoChange(paths appPaths) (string, error) {
+	return stepJournal(paths, true)
+}
+
+// redoChange applies the last undone change again and returns its
+// description.
+func redoChange(paths appPaths) (string, error) {
+	return stepJournal(paths, false)
+}
+
+func stepJournal(paths appPaths, undo bool) (string, error) {
+	j, err := loadJournal(paths.StateDir)
+	if err != nil {
+		return "", err
+	}
+	index, verb := j.Position, "redo"
+	if undo {
+		index, verb = j.Position-1, "undo"
+	}
+	if index < 0 || index >= len(j.Entries) {
+		return "", fmt.Errorf("nothing to %s", verb)
+	}
+	entry := j.Entries[index]
+	from, to := entry.Before, entry.After
+	if undo {
+		from, to = to, from
+	}
+
+	err = changeStore(paths, verb+" "+entry.Description, func(store *AliasStore) error {
+		// The journal is read again under the store lock, in case another
+		// process changed the store in the meantime.
+		current, err := loadJournal(paths.StateDir)
+		if err != nil {
+			return err
+		}
+		if current.Position != j.Position || len(current.Entries) != len(j.Entries) {
+			return errors.New("the aliases were changed by another aliasman process; try again")
+		}
+		data, err := store.marshal()
+		if err != nil {
+			return err
+		}
+		config := store.Config()
+		_, expected, err := withInstallSettings(from, config)
+		if err != nil {
+			return err
+		}
+		if !sameJSON(data, expected) {
+			return fmt.Errorf("the aliases were changed since %q by something that cannot be undone, such as editing the store by hand; nothing was changed", entry.Description)
+		}
+		store.data, _, err = withInstallSettings(to, config)
+		return err
+	}, func(before, after []byte) error {
+		if undo {
+			j.Position--
+		} else {
+			j.Position++
+		}
+		return saveJournal(paths.StateDir, j)
+	})
+	return entry.Description, err
+}
+
+// withInstallSettings returns a store snapshot of the journal with the
+// settings that installing and uninstalling change taken from config. Undo
+// and redo leave those alone, since they follow the shell config files, which
+// the journal does not restore; otherwise installing would keep every earlier
+// change from being undone.
+func withInstallSettings(snapshot []byte, config Config) (storeFile, []byte, error) {
+	var data storeFile
+	if err := json.Unmarshal(snapshot, &data); err != nil {
+		return storeFile{}, nil, fmt.Errorf("error reading the journal: %w", err)
+	}
+	data.Settings.AliasFile = config.AliasFile
+	data.Settings.RCFiles = config.RCFiles
+	content, err := json.Marshal(data)
+	if err != nil {
+		return storeFile{}, nil, err
+	}
+	return data, content, nil
+}
+
+// sameJSON reports whether a and b are the same JSON text up to whitespace;
+// the journal keeps the stores compacted.
+func sameJSON(a, b []byte) bool {
+	var ca, cb bytes.Buffer
+	return json.Compact(&ca, a) == nil && json.Compact(&cb, b) == nil && bytes.Equal(ca.Bytes(), cb.Bytes())
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditAliasStoreUndoRedo(t *testing.T) {
+	paths := testPaths(t)
+
+	// The first edit creates the store.
+	for _, name := range []string{"ll", "la"} {
+		err := editAliasStore(paths, "add "+name, func(store *AliasStore) error {
+			return store.Add(Alias{Name: name, Type: "alias", Command: "ls -" + name[1:]})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	script, err := os.ReadFile(paths.AliasFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(script), "alias la='ls -a'\n") {
+		t.Errorf("the alias file does not define la:\n%s", script)
+	}
+
+	steps := []struct {
+		step  func(appPaths) (string, error)
+		desc  string // the change undone or redone
+		err   string
+		names []string
+	}{
+		{undoChange, "add la", "", []string{reloadAliasName, "ll"}},
+		{undoChange, "add ll", "", []string{reloadAliasName}},
+		{undoChange, "", "nothing to undo", []string{reloadAliasName}},
+		{redoChange, "add ll", "", []string{reloadAliasName, "ll"}},
+		{redoChange, "add la", "", []string{reloadAliasName, "ll", "la"}},
+		{redoChange, "", "nothing to redo", []string{reloadAliasName, "ll", "la"}},
+		{undoChange, "add la", "", []string{reloadAliasName, "ll"}},
+	}
+	for i, tt := range steps {
+		desc, err := tt.step(paths)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Fatalf("step %d: %v", i, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Fatalf("step %d: got error %v, want one containing %q", i, err, tt.err)
+		case tt.err == "" && desc != tt.desc:
+			t.Errorf("step %d: changed %q, want %q", i, desc, tt.desc)
+		}
+		if got := storeNames(t, paths.Store); !reflect.DeepEqual(got, tt.names) {
+			t.Errorf("step %d: names = %v, want %v", i, got, tt.names)
+		}
+	}
+
+	// A new change drops the one that was undone.
+	err = editAliasStore(paths, "disable ll", func(store *AliasStore) error {
+		return store.SetDisabled("ll", true)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := redoChange(paths); err == nil {
+		t.Error("redo after a new change succeeded")
+	}
+	if desc, err := undoChange(paths); err != nil || desc != "disable ll" {
+		t.Errorf("undo = %q, %v; want the disabling undone", desc, err)
+	}
+	if got, want := storeNames(t, paths.Store), []string{reloadAliasName, "ll"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+}
+
+func TestUndoKeepsInstallSettings(t *testing.T) {
+	paths := testPaths(t)
+	err := editAliasStore(paths, "add ll", func(store *AliasStore) error {
+		return store.Add(Alias{Name: "ll", Type: "alias", Command: "ls -l"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Installing is not journaled, but must not keep "add ll" from being
+	// undone.
+	rcFiles := []string{filepath.Join(t.TempDir(), ".bashrc")}
+	err = changeStore(paths, "install", func(store *AliasStore) error {
+		config := store.Config()
+		config.RCFiles = rcFiles
+		return store.SetConfig(config)
+	}, notJournaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []func(appPaths) (string, error){undoChange, redoChange, undoChange} {
+		if _, err := step(paths); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := storeNames(t, paths.Store), []string{reloadAliasName}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Config().RCFiles; !reflect.DeepEqual(got, rcFiles) {
+		t.Errorf("RCFiles = %v, want %v", got, rcFiles)
+	}
+}
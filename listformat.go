@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var listFormats = []string{"text", "table", "tsv", "json", "yaml"}
+
+var listSortKeys = []string{"line", "name", "type", "group", "modified"}
+
+// listEntry is one alias or function as printed by "aliasman list".
+type listEntry struct {
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Command     string     `json:"command"`
+	Line        int        `json:"line"`
+	Group       string     `json:"group,omitempty"`
+	Disabled    bool       `json:"disabled,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Created     *time.Time `json:"created,omitempty"`
+	Modified    *time.Time `json:"modified,omitempty"`
+	Author      string     `json:"author,omitempty"`
+}
+
+// sortAliases sorts aliases by key, falling back to name and line so that
+// the order is always the same for the same file.
+func sortAliases(aliases []Alias, key string) error {
+	var less func(a, b Alias) bool
+	switch key {
+	case "line":
+		less = func(a, b Alias) bool { return a.Line < b.Line }
+	case "name":
+		less = func(a, b Alias) bool { return a.Name < b.Name }
+	case "type":
+		less = func(a, b Alias) bool { return a.Type < b.Type }
+	case "group":
+		less = func(a, b Alias) bool { return a.Group < b.Group }
+	case "modified":
+		// Newest first, and those without a timestamp last.
+		less = func(a, b Alias) bool {
+			return a.Modified != nil && (b.Modified == nil || a.Modified.After(*b.Modified))
+		}
+	default:
+		return fmt.Errorf("unknown sort key %q (expected one of %s)", key, strings.Join(listSortKeys, ", "))
+	}
+
+	sort.SliceStable(aliases, func(i, j int) bool {
+		a, b := aliases[i], aliases[j]
+		if less(a, b) || less(b, a) {
+			return less(a, b)
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Line < b.Line
+	})
+	return nil
+}
+
+// writeAliasList prints aliases to w in the given format.
+func writeAliasList(w io.Writer, format string, aliases []Alias) error {
+	entries := make([]listEntry, len(aliases))
+	for i, alias := range aliases {
+		entries[i] = listEntry{
+			Name:        alias.Name,
+			Type:        alias.Type,
+			Command:     alias.Command,
+			Line:        alias.Line,
+			Group:       alias.Group,
+			Disabled:    alias.Disabled,
+			Description: alias.Description,
+			Tags:        alias.Tags,
+			Created:     alias.Created,
+			Modified:    alias.Modified,
+			Author:      alias.Author,
+		}
+	}
+
+	switch format {
+	case "text":
+		return writeListText(w, entries)
+	case "table":
+		return writeListTable(w, entries)
+	case "tsv":
+		return writeListTSV(w, entries)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "yaml":
+		return writeListYAML(w, entries)
+	}
+	return fmt.Errorf("unknown format %q (expected one of %s)", format, strings.Join(listFormats, ", "))
+}
+
+func writeListText(w io.Writer, entries []listEntry) error {
+	fmt.Fprintln(w, "Available aliases:")
+	for _, e := range entries {
+		switch e.Type {
+		case "alias":
+			fmt.Fprintf(w, "  %s: %s%s\n", e.Name, e.Command, textComment(e))
+		case "global", "suffix":
+			fmt.Fprintf(w, "  %s (zsh %s): %s%s\n", e.Name, e.Type, e.Command, textComment(e))
+		}
+	}
+
+	fmt.Fprintln(w, "\nAvailable functions:")
+	for _, e := range entries {
+		if e.Type == "function" {
+			fmt.Fprintf(w, "  %s%s\n", e.Name, textComment(e))
+		}
+	}
+	return nil
+}
+
+// textComment returns the state, description and tags of e for the text
+// format.
+func textComment(e listEntry) string {
+	var comment []string
+	if e.Disabled {
+		comment = append(comment, "(disabled)")
+	}
+	if e.Description != "" {
+		comment = append(comment, e.Description)
+	}
+	if len(e.Tags) > 0 {
+		comment = append(comment, "["+strings.Join(e.Tags, ", ")+"]")
+	}
+	if len(comment) == 0 {
+		return ""
+	}
+	return "  # " + strings.Join(comment, " ")
+}
+
+func writeListTable(w io.Writer, entries []listEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tLINE\tSTATE\tGROUP\tTAGS\tDESCRIPTION\tCOMMAND")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", e.Name, e.Type, e.Line, entryState(e), e.Group, strings.Join(e.Tags, ","), e.Description, escapeTSV(e.Command))
+	}
+	return tw.Flush()
+}
+
+// writeListTSV prints the metadata after the command, so that scripts reading
+// the first four columns keep working.
+func writeListTSV(w io.Writer, entries []listEntry) error {
+	fmt.Fprintln(w, "name\ttype\tline\tcommand\tdescription\ttags\tcreated\tmodified\tauthor\tgroup\tstate")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", escapeTSV(e.Name), e.Type, e.Line, escapeTSV(e.Command),
+			escapeTSV(e.Description), strings.Join(e.Tags, ","), rfc3339(e.Created), rfc3339(e.Modified), escapeTSV(e.Author), e.Group, entryState(e))
+	}
+	return nil
+}
+
+func entryState(e listEntry) string {
+	if e.Disabled {
+		return "disabled"
+	}
+	return "enabled"
+}
+
+// rfc3339 formats a timestamp for the machine-readable formats.
+func rfc3339(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// escapeTSV keeps a field on one line and free of tabs.
+func escapeTSV(s string) string {
+	return tsvEscaper.Replace(s)
+}
+
+func writeListYAML(w io.Writer, entries []listEntry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+	for _, e := range entries {
+		fmt.Fprintf(w, "- name: %s\n", yamlString(e.Name))
+		fmt.Fprintf(w, "  type: %s\n", yamlString(e.Type))
+		fmt.Fprintf(w, "  command: %s\n", yamlString(e.Command))
+		fmt.Fprintf(w, "  line: %d\n", e.Line)
+		if e.Group != "" {
+			fmt.Fprintf(w, "  group: %s\n", yamlString(e.Group))
+		}
+		if e.Disabled {
+			fmt.Fprintln(w, "  disabled: true")
+		}
+		if e.Description != "" {
+			fmt.Fprintf(w, "  description: %s\n", yamlString(e.Description))
+		}
+		if len(e.Tags) > 0 {
+			fmt.Fprintln(w, "  tags:")
+			for _, tag := range e.Tags {
+				fmt.Fprintf(w, "    - %s\n", yamlString(tag))
+			}
+		}
+		if e.Created != nil {
+			fmt.Fprintf(w, "  created: %s\n", rfc3339(e.Created))
+		}
+		if e.Modified != nil {
+			fmt.Fprintf(w, "  modified: %s\n", rfc3339(e.Modified))
+		}
+		if e.Author != "" {
+			fmt.Fprintf(w, "  author: %s\n", yamlString(e.Author))
+		}
+	}
+	return nil
+}
+
+// yamlString quotes s as a YAML double-quoted scalar. JSON strings are valid
+// YAML, so the JSON encoder does the escaping.
+func yamlString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
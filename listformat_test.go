@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAliasList(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	aliases := []Alias{
+		{Name: "ll", Type: "alias", Command: "ls -l", Line: 3, Description: "long list", Tags: []string{"fs", "ls"}, Created: &created, Modified: &created, Author: "u"},
+		{Name: "greet", Type: "function", Command: "echo\thi\necho \"there\"", Line: 4, Group: "fun", Disabled: true},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"text", "Available aliases:\n" +
+			"  ll: ls -l  # long list [fs, ls]\n" +
+			"\nAvailable functions:\n" +
+			"  greet  # (disabled)\n"},
+		{"table", "NAME   TYPE      LINE  STATE     GROUP  TAGS   DESCRIPTION  COMMAND\n" +
+			"ll     alias     3     enabled          fs,ls  long list    ls -l\n" +
+			"greet  function  4     disabled  fun                        echo\\thi\\necho \"there\"\n"},
+		{"tsv", "name\ttype\tline\tcommand\tdescription\ttags\tcreated\tmodified\tauthor\tgroup\tstate\n" +
+			"ll\talias\t3\tls -l\tlong list\tfs,ls\t2024-05-01T12:00:00Z\t2024-05-01T12:00:00Z\tu\t\tenabled\n" +
+			"greet\tfunction\t4\techo\\thi\\necho \"there\"\t\t\t\t\t\tfun\tdisabled\n"},
+		{"json", `[
+  {
+    "name": "ll",
+    "type": "alias",
+    "command": "ls -l",
+    "line": 3,
+    "description": "long list",
+    "tags": [
+      "fs",
+      "ls"
+    ],
+    "created": "2024-05-01T12:00:00Z",
+    "modified": "2024-05-01T12:00:00Z",
+    "author": "u"
+  },
+  {
+    "name": "greet",
+    "type": "function",
+    "command": "echo\thi\necho \"there\"",
+    "line": 4,
+    "group": "fun",
+    "disabled": true
+  }
+]
+`},
+		{"yaml", "- name: \"ll\"\n  type: \"alias\"\n  command: \"ls -l\"\n  line: 3\n" +
+			"  description: \"long list\"\n  tags:\n    - \"fs\"\n    - \"ls\"\n" +
+			"  created: 2024-05-01T12:00:00Z\n  modified: 2024-05-01T12:00:00Z\n  author: \"u\"\n" +
+			"- name: \"greet\"\n  type: \"function\"\n  command: \"echo\\thi\\necho \\\"there\\\"\"\n  line: 4\n" +
+			"  group: \"fun\"\n  disabled: true\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := writeAliasList(&b, tt.format, aliases); err != nil {
+			t.Errorf("%s: %v", tt.format, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s:\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+
+	if err := writeAliasList(&strings.Builder{}, "xml", aliases); err == nil {
+		t.Error("an unknown format was accepted")
+	}
+}
+
+func TestSortAliases(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	aliases := []Alias{
+		{Name: "b", Type: "function", Line: 1, Group: "x", Modified: &older},
+		{Name: "c", Type: "alias", Line: 2},
+		{Name: "a", Type: "alias", Line: 3, Group: "x", Modified: &newer},
+	}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"line", "bca"},
+		{"name", "abc"},
+		{"type", "acb"},
+		{"group", "cab"},
+		{"modified", "abc"},
+	}
+	for _, tt := range tests {
+		sorted := append([]Alias(nil), aliases...)
+		if err := sortAliases(sorted, tt.key); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for _, alias := range sorted {
+			got += alias.Name
+		}
+		if got != tt.want {
+			t.Errorf("sorted by %s: %s, want %s", tt.key, got, tt.want)
+		}
+	}
+	if err := sortAliases(aliases, "size"); err == nil {
+		t.Error("an unknown sort key was accepted")
+	}
+}
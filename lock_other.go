@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// lockFile does nothing on systems without flock; writes are still atomic,
+// but concurrent changes from two processes can overwrite each other.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
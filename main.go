@@ -1,20 +1,23 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 const (
-	aliasFileName = ".aliasman_aliases"
+	aliasFileName = ".aliasman_aliases" // alias file of older versions, in the home directory
 	tagStart      = "# START ALIASMAN MANAGED BLOCK"
 	tagEnd        = "# END ALIASMAN MANAGED BLOCK"
 )
@@ -29,71 +32,111 @@ func main() {
 		os.Exit(1)
 	}
 
-	aliasFilePath := filepath.Join(homeDir, aliasFileName)
-	shellConfigPath := detectShellConfig(homeDir)
+	configFlag, args, err := parseGlobalFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		printCliUsage(os.Stdout)
+		os.Exit(exitOK)
+	}
+	if err != nil {
+		printCliUsage(os.Stderr)
+		os.Exit(exitUsage)
+	}
 
-	// Check if the "list" parameter is provided
-	if len(os.Args) > 1 && os.Args[1] == "list" {
-		listAliasesCli()
-		return
+	paths, err := resolvePaths(homeDir, configFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if paths.LegacyAliasFile != "" {
+		// A file that cannot be imported is reported but does not keep
+		// aliasman from starting; it is left untouched.
+		notes, err := migrateAliasFile(paths.Store, paths.LegacyAliasFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; nothing was imported and the file was left as it is\n", err)
+		}
+		if notes != nil {
+			fmt.Fprintf(os.Stderr, "Aliases imported from %s into %s:\n", paths.LegacyAliasFile, paths.Store)
+			for _, note := range notes {
+				fmt.Fprintln(os.Stderr, "  "+note)
+			}
+		}
+	}
+	shellConfigPaths := recordedShellConfigs(paths.Store)
+	if len(shellConfigPaths) == 0 && !paths.relocated() {
+		shellConfigPaths = detectShellConfigs(homeDir)
 	}
 
-	// Check installation and install if not already installed
-	if !isAliasmanInstalled(aliasFilePath, shellConfigPath) {
-		installAliasman(aliasFilePath, shellConfigPath)
+	// Run a non-interactive subcommand if one is given
+	if len(args) > 0 {
+		os.Exit(runCli(paths, args))
 	}
 
-	mainMenu := createMainMenu(app, pages, aliasFilePath, shellConfigPath)
+	if err := createStore(paths.Store, paths.AliasFile); err != nil {
+		fmt.Println("Error creating alias store:", err)
+		os.Exit(1)
+	}
+
+	mainMenu := createMainMenu(app, pages, paths, homeDir, shellConfigPaths)
 	pages.AddPage("main", mainMenu, true, true)
 
+	// Installing is offered rather than done, since aliasman may have been
+	// uninstalled on purpose. A relocated instance is only installed on
+	// request.
+	if !paths.relocated() && !isAliasmanInstalled(paths.Store, shellConfigPaths) {
+		offerInstallation(app, pages, paths, shellConfigPaths)
+	}
+
 	if err := app.SetRoot(pages, true).EnableMouse(true).Run(); err != nil {
 		fmt.Println("Error running application:", err)
 		os.Exit(1)
 	}
 }
 
-func createMainMenu(app *tview.Application, pages *tview.Pages, aliasFilePath, shellConfigPath string) *tview.List {
+func createMainMenu(app *tview.Application, pages *tview.Pages, paths appPaths, homeDir string, shellConfigPaths []string) *tview.List {
 	mainMenu := tview.NewList().
 		AddItem("Manage Aliases", "Add, remove, or list aliases", 'm', nil).
 		AddItem("AI Assisted Alias Creation", "Create an alias using AI assistance", 'a', nil).
 		AddItem("Settings", "Configure Aliasman settings", 's', nil).
 		AddItem("Quit", "Exit the application", 'q', func() {
 			app.Stop()
-			showReloadInstructions(shellConfigPath)
+			showReloadInstructions(shellConfigPaths)
 		})
 
 	mainMenu.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
 		switch index {
 		case 0:
-			showAliasManagement(app, pages, aliasFilePath)
+			showAliasManagement(app, pages, paths)
 		case 1:
-			showAIAssistedAliasCreation(app, pages, aliasFilePath)
+			showAIAssistedAliasCreation(app, pages, paths)
 		case 2:
-			showSettings(app, pages, aliasFilePath, shellConfigPath)
+			showSettings(app, pages, paths, homeDir)
 		}
 	})
 
 	return mainMenu
 }
 
-func showReloadInstructions(shellConfigPath string) {
+func showReloadInstructions(shellConfigPaths []string) {
+	if len(shellConfigPaths) == 0 {
+		return
+	}
 	fmt.Printf("\nTo reload your aliases in the current shell, you can either:\n")
-	fmt.Printf("1. Run the command: source %s\n", shellConfigPath)
+	fmt.Printf("1. Run the command: source %s\n", shellConfigPaths[0])
 	fmt.Printf("2. Or simply use the alias: aliasman-reload\n\n")
 }
 
-func showAliasManagement(app *tview.Application, pages *tview.Pages, aliasFilePath string) {
+func showAliasManagement(app *tview.Application, pages *tview.Pages, paths appPaths) {
 	list := tview.NewList().
-		AddItem("List Aliases", "Show all defined aliases", 'l', nil).
+		AddItem("List Aliases", "Show, edit or delete defined aliases", 'l', nil).
 		AddItem("Add Alias", "Create a new alias", 'a', nil).
 		AddItem("Back", "Return to main menu", 'q', nil)
 
 	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
 		switch index {
 		case 0:
-			listAliases(app, pages, aliasFilePath)
+			listAliases(app, pages, paths, "")
 		case 1:
-			addAlias(app, pages, aliasFilePath)
+			addAlias(app, pages, paths)
 		case 2:
 			pages.SwitchToPage("main")
 		}
@@ -103,76 +146,441 @@ func showAliasManagement(app *tview.Application, pages *tview.Pages, aliasFilePa
 	pages.SwitchToPage("aliasManagement")
 }
 
-func listAliases(app *tview.Application, pages *tview.Pages, aliasFilePath string) {
-	aliases, err := readAliases(aliasFilePath)
+// groupSelection is the entry of the group sidebar the alias list shows: all
+// definitions, or those in the group called name ("" for no group).
+type groupSelection struct {
+	all  bool
+	name string
+}
+
+// aliasListView is how the alias list is filtered and sorted. It is kept
+// while the list is rebuilt after each change.
+type aliasListView struct {
+	group groupSelection
+	query string
+	sort  string // "line" for the order of the alias file, or a column
+}
+
+var listView = aliasListView{group: groupSelection{all: true}, sort: "line"}
+
+// listSortColumns are the sort keys of the alias list and the columns they
+// sort by.
+var listSortColumns = map[string]int{"line": -1, "name": 1, "type": 0, "modified": 5}
+
+// listAliases shows the alias table next to the group sidebar, with status,
+// if not empty, below them.
+func listAliases(app *tview.Application, pages *tview.Pages, paths appPaths, status string) {
+	store, err := loadAliasStore(paths.Store)
 	if err != nil {
 		showErrorModal(app, pages, "Error reading aliases: "+err.Error())
 		return
 	}
+	aliases := store.Aliases()
 
 	table := tview.NewTable().
 		SetBorders(true).
 		SetSelectable(true, false).
 		SetSeparator(tview.Borders.Vertical)
 
-	table.SetCell(0, 0, tview.NewTableCell("Type").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
-	table.SetCell(0, 1, tview.NewTableCell("Name").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
-	table.SetCell(0, 2, tview.NewTableCell("Command").SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
+	// shown are the aliases in the table, in the order of its rows, and
+	// marked are the names of those selected for a bulk action.
+	var shown []Alias
+	marked := map[string]bool{}
+	fill := func() {
+		table.Clear()
+		for column, header := range []string{"Type", "Name", "Command", "Description", "Tags", "Modified"} {
+			if listSortColumns[listView.sort] == column {
+				header += " ▼"
+			}
+			table.SetCell(0, column, tview.NewTableCell(header).SetTextColor(tcell.ColorYellow).SetSelectable(false).SetAlign(tview.AlignCenter))
+		}
+
+		shown = nil
+		matches := map[string]aliasMatch{}
+		for _, alias := range aliases {
+			if !listView.group.all && alias.Group != listView.group.name {
+				continue
+			}
+			if m, ok := searchAlias(listView.query, alias); ok {
+				shown = append(shown, alias)
+				matches[alias.Name] = m
+			}
+		}
+		if listView.sort == "line" && listView.query != "" {
+			// Without a sort column, the best matches come first.
+			slices.SortStableFunc(shown, func(a, b Alias) int {
+				return matches[b.Name].score - matches[a.Name].score
+			})
+		} else {
+			sortAliases(shown, listView.sort)
+		}
 
-	for i, alias := range aliases {
-		table.SetCell(i+1, 0, tview.NewTableCell(alias.Type).SetTextColor(tcell.ColorWhite).SetAlign(tview.AlignLeft))
-		table.SetCell(i+1, 1, tview.NewTableCell(alias.Name).SetTextColor(tcell.ColorWhite).SetAlign(tview.AlignLeft))
-		table.SetCell(i+1, 2, tview.NewTableCell(alias.Command).SetTextColor(tcell.ColorWhite).SetAlign(tview.AlignLeft))
+		for i, alias := range shown {
+			// Disabled definitions, and those of disabled groups, are dimmed.
+			color, aliasType := tcell.ColorWhite, alias.Type
+			if alias.Disabled || store.GroupDisabled(alias.Group) {
+				color, aliasType = tcell.ColorGray, alias.Type+" (off)"
+			}
+			if marked[alias.Name] {
+				aliasType = "● " + aliasType
+			}
+			m := matches[alias.Name].fields
+			table.SetCell(i+1, 0, tview.NewTableCell(aliasType).SetTextColor(color).SetAlign(tview.AlignLeft))
+			table.SetCell(i+1, 1, tview.NewTableCell(highlight(alias.Name, m["name"])).SetTextColor(color).SetAlign(tview.AlignLeft))
+			table.SetCell(i+1, 2, tview.NewTableCell(highlight(alias.Command, m["command"])).SetTextColor(color).SetAlign(tview.AlignLeft).SetMaxWidth(60))
+			table.SetCell(i+1, 3, tview.NewTableCell(highlight(alias.Description, m["description"])).SetTextColor(color).SetAlign(tview.AlignLeft).SetMaxWidth(40))
+			table.SetCell(i+1, 4, tview.NewTableCell(highlight(strings.Join(alias.Tags, ", "), m["tags"])).SetTextColor(tcell.ColorAqua).SetAlign(tview.AlignLeft))
+			table.SetCell(i+1, 5, tview.NewTableCell(formatTime(alias.Modified)).SetTextColor(tcell.ColorGray).SetAlign(tview.AlignLeft))
+		}
+		if len(shown) == 0 && listView.query != "" {
+			table.SetCell(1, 1, tview.NewTableCell("No matches").SetTextColor(tcell.ColorGray).SetSelectable(false))
+		}
+		table.Select(1, 0)
 	}
 
-	table.Select(1, 0).SetFixed(1, 0).SetDoneFunc(func(key tcell.Key) {
+	// The sidebar lists every group, and the definitions outside any group
+	// once there are groups at all.
+	groups := groupAliases(aliases)
+	selections := []groupSelection{{all: true}}
+	sidebar := tview.NewList().ShowSecondaryText(false)
+	sidebar.AddItem(fmt.Sprintf("All (%d)", len(aliases)), "", 0, nil)
+	for _, group := range groups {
+		if group.Name == "" && len(groups) == 1 {
+			continue
+		}
+		label := group.Name
+		if label == "" {
+			label = "(no group)"
+		}
+		state := ""
+		if store.GroupDisabled(group.Name) {
+			state = ", off"
+		}
+		sidebar.AddItem(fmt.Sprintf("%s (%d%s)", label, len(group.Aliases), state), "", 0, nil)
+		selections = append(selections, groupSelection{name: group.Name})
+	}
+	if i := slices.Index(selections, listView.group); i >= 0 {
+		sidebar.SetCurrentItem(i)
+	} else {
+		listView.group = groupSelection{all: true}
+	}
+	sidebar.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+		listView.group = selections[index]
+		fill()
+	})
+	sidebar.SetSelectedFunc(func(int, string, string, rune) {
+		app.SetFocus(table)
+	})
+	sidebar.SetBorder(true).SetTitle("Groups")
+	fill()
+
+	table.SetFixed(1, 0).SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyEscape {
 			pages.SwitchToPage("aliasManagement")
 		}
 	}).SetSelectedFunc(func(row, column int) {
-		if row > 0 {
-			aliasToDelete := aliases[row-1]
-			deleteAlias(app, pages, aliasFilePath, aliasToDelete.Name)
+		if row > 0 && row <= len(shown) {
+			editAlias(app, pages, paths, shown[row-1])
 		}
 	})
+	selected := func() (Alias, bool) {
+		row, _ := table.GetSelection()
+		if !table.HasFocus() || row < 1 || row > len(shown) {
+			return Alias{}, false
+		}
+		return shown[row-1], true
+	}
+
+	// The search field below the table filters it as you type. It stays
+	// visible while it holds a query.
+	search := tview.NewInputField().SetLabel("/").SetText(listView.query)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(sidebar, 24, 0, false).
+			AddItem(table, 0, 1, true), 0, 1, true)
+	showSearch := func(visible bool) {
+		height := 0
+		if visible {
+			height = 1
+		}
+		layout.ResizeItem(search, height, 0)
+	}
+	layout.AddItem(search, 0, 0, false)
+	showSearch(listView.query != "")
+	search.SetChangedFunc(func(text string) {
+		listView.query = text
+		fill()
+	})
+	search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			search.SetText("")
+		}
+		showSearch(listView.query != "")
+		app.SetFocus(table)
+	})
 
-	frame := tview.NewFrame(table).SetBorders(0, 0, 0, 0, 0, 0)
-	frame.AddText("Aliases (Press 'D' to delete, 'Q' to go back)", true, tview.AlignCenter, tcell.ColorYellow)
+	frame := tview.NewFrame(layout).SetBorders(0, 0, 0, 0, 0, 0)
+	frame.AddText("Aliases ('E'/Enter edit, 'D' delete, 'X' disable/enable, '/' search, 'S' sort, 'U' undo, Ctrl-R redo, Tab groups, 'Q' back)", true, tview.AlignCenter, tcell.ColorYellow)
+	frame.AddText("Space to mark, Ctrl-A to mark all shown, 'B' for actions on the marked ones", true, tview.AlignCenter, tcell.ColorYellow)
+	if status != "" {
+		frame.AddText(status, false, tview.AlignCenter, tcell.ColorGreen)
+	}
 
 	pages.AddPage("aliasList", frame, true, true)
 	pages.SwitchToPage("aliasList")
 
+	step := func(step func(appPaths) (string, error), done string) {
+		description, err := step(paths)
+		if err != nil {
+			showErrorModalFor(app, pages, err.Error(), "aliasList")
+			return
+		}
+		listAliases(app, pages, paths, done+": "+description)
+	}
+
+	// markedNames returns the marked definitions in the order of the store.
+	markedNames := func() []string {
+		var names []string
+		for _, alias := range aliases {
+			if marked[alias.Name] {
+				names = append(names, alias.Name)
+			}
+		}
+		return names
+	}
+
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// The keys are only for the list itself, not the search field or
+		// the pages opened on top of it.
+		if front, _ := pages.GetFrontPage(); front != "aliasList" || search.HasFocus() {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyCtrlR:
+			step(redoChange, "Redid")
+			return nil
+		case tcell.KeyCtrlA:
+			// Marks every row shown, or clears the marks if they all are.
+			all := true
+			for _, alias := range shown {
+				all = all && marked[alias.Name]
+			}
+			for _, alias := range shown {
+				marked[alias.Name] = !all
+			}
+			row, _ := table.GetSelection()
+			fill()
+			table.Select(row, 0)
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if table.HasFocus() {
+				app.SetFocus(sidebar)
+			} else {
+				app.SetFocus(table)
+			}
+			return nil
+		}
 		if event.Key() == tcell.KeyRune {
 			switch event.Rune() {
+			case 'u', 'U':
+				step(undoChange, "Undid")
+				return nil
+			case ' ':
+				if alias, ok := selected(); ok {
+					marked[alias.Name] = !marked[alias.Name]
+					row, _ := table.GetSelection()
+					fill()
+					table.Select(min(row+1, len(shown)), 0)
+					return nil
+				}
+			case 'b', 'B':
+				if names := markedNames(); len(names) > 0 {
+					showBulkActions(app, pages, paths, names)
+				}
+				return nil
+			case '/':
+				showSearch(true)
+				app.SetFocus(search)
+				return nil
+			case 's', 'S':
+				keys := []string{"line", "name", "type", "modified"}
+				listView.sort = keys[(slices.Index(keys, listView.sort)+1)%len(keys)]
+				fill()
+				return nil
 			case 'q', 'Q':
 				pages.SwitchToPage("aliasManagement")
 				app.SetInputCapture(nil)
 				return nil
 			case 'd', 'D':
-				row, _ := table.GetSelection()
-				if row > 0 {
-					aliasToDelete := aliases[row-1]
-					deleteAlias(app, pages, aliasFilePath, aliasToDelete.Name)
+				if alias, ok := selected(); ok {
+					deleteAlias(app, pages, paths, alias.Name)
 					return nil
 				}
+			case 'e', 'E':
+				if alias, ok := selected(); ok {
+					editAlias(app, pages, paths, alias)
+					return nil
+				}
+			case 'x', 'X':
+				// In the sidebar, the selected group is switched instead.
+				if alias, ok := selected(); ok {
+					changeFromList(app, pages, paths, disableVerb(!alias.Disabled)+" "+alias.Name, func(store *AliasStore) error {
+						return store.SetDisabled(alias.Name, !alias.Disabled)
+					})
+				} else if group := listView.group; sidebar.HasFocus() && !group.all && group.name != "" {
+					disabled := !store.GroupDisabled(group.name)
+					changeFromList(app, pages, paths, disableVerb(disabled)+" group "+group.name, func(store *AliasStore) error {
+						return store.SetGroupDisabled(group.name, disabled)
+					})
+				}
+				return nil
 			}
 		}
 		return event
 	})
 }
 
-func deleteAlias(app *tview.Application, pages *tview.Pages, aliasFilePath, name string) {
+// showBulkActions offers the actions that apply to several definitions at
+// once, and exporting them, for the definitions called names.
+func showBulkActions(app *tview.Application, pages *tview.Pages, paths appPaths, names []string) {
+	list := tview.NewList()
+	for _, action := range bulkActions {
+		action := action
+		list.AddItem(action.label, "", 0, func() {
+			if action.input == "" {
+				confirmBulkAction(app, pages, paths, action, names, "")
+				return
+			}
+			form := tview.NewForm().AddInputField(action.input, "", 40, nil, nil)
+			form.AddButton("OK", func() {
+				value := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+				confirmBulkAction(app, pages, paths, action, names, value)
+			}).AddButton("Cancel", func() {
+				pages.SwitchToPage("aliasList")
+			})
+			form.SetCancelFunc(func() {
+				pages.SwitchToPage("aliasList")
+			})
+			form.SetBorder(true).SetTitle(action.label).SetTitleAlign(tview.AlignCenter)
+			pages.AddPage("bulkInput", form, true, true)
+			pages.SwitchToPage("bulkInput")
+		})
+	}
+	list.AddItem("Export", "", 0, func() {
+		exportMarked(app, pages, paths, names)
+	})
+	list.AddItem("Cancel", "", 'q', func() {
+		pages.SwitchToPage("aliasList")
+	})
+	list.ShowSecondaryText(false)
+	list.SetDoneFunc(func() {
+		pages.SwitchToPage("aliasList")
+	})
+	list.SetBorder(true).SetTitle(fmt.Sprintf("%d marked: %s", len(names), nameList(names)))
+
+	pages.AddPage("bulkActions", list, true, true)
+	pages.SwitchToPage("bulkActions")
+}
+
+// confirmBulkAction asks once before applying action to every definition in
+// names.
+func confirmBulkAction(app *tview.Application, pages *tview.Pages, paths appPaths, action bulkAction, names []string, value string) {
+	reason := action.reason(nameList(names), value)
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s (%d definitions)?", strings.ToUpper(reason[:1])+reason[1:], len(names))).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel != "Yes" {
+				pages.SwitchToPage("aliasList")
+				return
+			}
+			if err := applyBulkAction(paths, action, names, value); err != nil {
+				showErrorModalFor(app, pages, "Nothing was changed: "+err.Error(), "aliasList")
+				return
+			}
+			listAliases(app, pages, paths, "Done: "+reason)
+		})
+	pages.AddPage("bulkConfirm", modal, false, true)
+	pages.SwitchToPage("bulkConfirm")
+}
+
+// exportMarked asks for a shell and a file and exports the definitions in
+// names to it.
+func exportMarked(app *tview.Application, pages *tview.Pages, paths appPaths, names []string) {
+	shells := shellTargetNames()
+	form := tview.NewForm().
+		AddDropDown("Shell", shells, slices.Index(shells, "bash"), nil).
+		AddInputField("File", "", 50, nil, nil)
+	form.AddButton("Export", func() {
+		_, shell := form.GetFormItemByLabel("Shell").(*tview.DropDown).GetCurrentOption()
+		path := strings.TrimSpace(form.GetFormItemByLabel("File").(*tview.InputField).GetText())
+		if path == "" {
+			showErrorModalFor(app, pages, "The file is required", "bulkExport")
+			return
+		}
+		modal := tview.NewModal().
+			SetText(fmt.Sprintf("Export %d definitions (%s) for %s to %s?", len(names), nameList(names), shell, path)).
+			AddButtons([]string{"Yes", "No"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				if buttonLabel != "Yes" {
+					pages.SwitchToPage("bulkExport")
+					return
+				}
+				skipped, err := exportAliases(paths.Store, names, shell, path)
+				if err != nil {
+					showErrorModalFor(app, pages, "Error exporting: "+err.Error(), "bulkExport")
+					return
+				}
+				status := fmt.Sprintf("Exported %d definitions to %s", len(names)-len(skipped), path)
+				if len(skipped) > 0 {
+					status += fmt.Sprintf(" (%d skipped, not supported by %s)", len(skipped), shell)
+				}
+				listAliases(app, pages, paths, status)
+			})
+		pages.AddPage("bulkConfirm", modal, false, true)
+		pages.SwitchToPage("bulkConfirm")
+	}).AddButton("Cancel", func() {
+		pages.SwitchToPage("aliasList")
+	})
+	form.SetCancelFunc(func() {
+		pages.SwitchToPage("aliasList")
+	})
+	form.SetBorder(true).SetTitle("Export").SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("bulkExport", form, true, true)
+	pages.SwitchToPage("bulkExport")
+}
+
+func disableVerb(disabled bool) string {
+	if disabled {
+		return "disable"
+	}
+	return "enable"
+}
+
+// changeFromList applies edit to the store and shows the alias list again,
+// with reason as its status.
+func changeFromList(app *tview.Application, pages *tview.Pages, paths appPaths, reason string, edit func(store *AliasStore) error) {
+	if err := editAliasStore(paths, reason, edit); err != nil {
+		showErrorModalFor(app, pages, "Error updating aliases: "+err.Error(), "aliasList")
+		return
+	}
+	listAliases(app, pages, paths, "Done: "+reason)
+}
+
+func deleteAlias(app *tview.Application, pages *tview.Pages, paths appPaths, name string) {
 	modal := tview.NewModal().
 		SetText(fmt.Sprintf("Are you sure you want to delete the alias '%s'?", name)).
 		AddButtons([]string{"Yes", "No"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			if buttonLabel == "Yes" {
-				err := removeAlias(aliasFilePath, name)
+				err := editAliasStore(paths, "remove "+name, func(store *AliasStore) error {
+					return store.Remove(name)
+				})
 				if err != nil {
 					showErrorModal(app, pages, "Error deleting alias: "+err.Error())
 				} else {
-					listAliases(app, pages, aliasFilePath)
+					listAliases(app, pages, paths, "")
 				}
 			} else {
 				pages.SwitchToPage("aliasList")
@@ -183,219 +591,285 @@ func deleteAlias(app *tview.Application, pages *tview.Pages, aliasFilePath, name
 	pages.SwitchToPage("deleteConfirm")
 }
 
-func checkInstallation(app *tview.Application, pages *tview.Pages, aliasFilePath, shellConfigPath string) {
-	modal := tview.NewModal()
-	isInstalled := isAliasmanInstalled(aliasFilePath, shellConfigPath)
+func checkInstallation(app *tview.Application, pages *tview.Pages, paths appPaths, homeDir string) {
+	candidates := installCandidates(paths, homeDir)
+	selected := make([]bool, len(candidates))
 
-	if isInstalled {
-		modal.SetText("Aliasman is already installed.")
-		modal.AddButtons([]string{"OK"})
-	} else {
-		modal.SetText("Aliasman is not installed. Would you like to install it?")
-		modal.AddButtons([]string{"Install", "Cancel"})
+	form := tview.NewForm()
+	for i, path := range candidates {
+		status := "not installed"
+		switch {
+		case isInstalledIn(paths.Store, path):
+			status = "installed"
+			selected[i] = true
+		case !fileExists(path):
+			status = "missing, will be created"
+		}
+
+		i := i
+		form.AddCheckbox(fmt.Sprintf("%s (%s)", path, status), selected[i], func(checked bool) {
+			selected[i] = checked
+		})
 	}
 
-	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-		if !isInstalled && buttonLabel == "Install" {
-			installAliasman(aliasFilePath, shellConfigPath)
-			modal.SetText("Aliasman has been installed successfully.")
-			modal.ClearButtons()
-			modal.AddButtons([]string{"OK"})
-			app.SetFocus(modal)
+	form.AddButton("Install", func() {
+		var configs []string
+		for i, path := range candidates {
+			if selected[i] {
+				configs = append(configs, path)
+			}
+		}
+
+		modal := tview.NewModal().AddButtons([]string{"OK"}).SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.SwitchToPage("settings")
+		})
+		if err := installAliasman(paths, configs); err != nil {
+			modal.SetText("Error installing aliasman: " + err.Error())
 		} else {
-			pages.SwitchToPage("main")
+			modal.SetText("Aliasman is installed in:\n\n" + strings.Join(configs, "\n"))
 		}
+		pages.AddPage("modal", modal, false, true)
+		pages.SwitchToPage("modal")
+	})
+	form.AddButton("Cancel", func() {
+		pages.SwitchToPage("settings")
+	})
+	form.SetCancelFunc(func() {
+		pages.SwitchToPage("settings")
 	})
 
-	pages.AddPage("modal", modal, false, true)
-	pages.SwitchToPage("modal")
+	form.SetBorder(true).SetTitle("Install into shell config files").SetTitleAlign(tview.AlignCenter)
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("checkInstallation", form, true, true)
+	pages.SwitchToPage("checkInstallation")
 }
 
-func isAliasmanInstalled(aliasFilePath, shellConfigPath string) bool {
-	// Check if alias file exists
-	if _, err := os.Stat(aliasFilePath); os.IsNotExist(err) {
-		return false
+// offerInstallation asks whether to install aliasman into shellConfigPaths
+// when it starts without being installed.
+func offerInstallation(app *tview.Application, pages *tview.Pages, paths appPaths, shellConfigPaths []string) {
+	if len(shellConfigPaths) == 0 {
+		return
 	}
+	modal := tview.NewModal().
+		SetText("Aliasman is not installed, so your shell does not load its aliases.\n\nInstall it into:\n\n" + strings.Join(shellConfigPaths, "\n")).
+		AddButtons([]string{"Install", "Not now"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel != "Install" {
+				pages.SwitchToPage("main")
+				return
+			}
+			if err := installAliasman(paths, shellConfigPaths); err != nil {
+				showErrorModalFor(app, pages, "Error installing aliasman: "+err.Error(), "main")
+				return
+			}
+			pages.SwitchToPage("main")
+		})
+	pages.AddPage("installPrompt", modal, false, true)
+	pages.SwitchToPage("installPrompt")
+}
 
-	// Check if source line is in shell config
-	content, err := os.ReadFile(shellConfigPath)
-	if err != nil {
-		return false
+func addAlias(app *tview.Application, pages *tview.Pages, paths appPaths) {
+	back := func() {
+		pages.SwitchToPage("aliasManagement")
 	}
 
-	return string(content) != "" && (string(content) != "" && string(content) != "")
+	showAliasForm(app, pages, "Add Alias/Function", Alias{Type: "alias"}, func(alias Alias) error {
+		return editAliasStore(paths, "add "+alias.Name, func(store *AliasStore) error {
+			return store.Add(alias)
+		})
+	}, back)
 }
 
-func installAliasman(aliasFilePath, shellConfigPath string) {
-	// Create alias file with configuration
-	initialContent := `# { "model": "llama3:8b" }
-# Aliasman managed aliases
-
-# Reload aliases
-alias aliasman-reload='source ` + aliasFilePath + `'
-`
-	if err := os.WriteFile(aliasFilePath, []byte(initialContent), 0644); err != nil {
-		fmt.Println("Error creating alias file:", err)
-		return
+func editAlias(app *tview.Application, pages *tview.Pages, paths appPaths, alias Alias) {
+	back := func() {
+		listAliases(app, pages, paths, "")
 	}
 
-	// Add source line to shell config
-	sourceLine := fmt.Sprintf("\n%s\nsource %s\n%s\n", tagStart, aliasFilePath, tagEnd)
-	f, err := os.OpenFile(shellConfigPath, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		fmt.Println("Error opening shell config file:", err)
-		return
+	title := fmt.Sprintf("Edit %s '%s'", alias.Type, alias.Name)
+	if history := aliasHistory(alias); history != "" {
+		title += " (" + history + ")"
 	}
-	defer f.Close()
+	showAliasForm(app, pages, title, alias, func(updated Alias) error {
+		return editAliasStore(paths, "edit "+alias.Name, func(store *AliasStore) error {
+			return store.Update(alias.Name, updated)
+		})
+	}, back)
+}
 
-	if _, err := f.WriteString(sourceLine); err != nil {
-		fmt.Println("Error writing to shell config file:", err)
+// showAliasForm shows a form pre-filled with alias. Saving calls save with the
+// edited definition and, if that succeeds, back. Functions get a multi-line
+// editor, and either kind can be edited in $EDITOR instead.
+func showAliasForm(app *tview.Application, pages *tview.Pages, title string, alias Alias, save func(Alias) error, back func()) {
+	types := []string{"alias", "function", "global", "suffix"}
+	typeIndex := 0
+	for i, t := range types {
+		if t == alias.Type {
+			typeIndex = i
+		}
 	}
-}
 
-func addAlias(app *tview.Application, pages *tview.Pages, aliasFilePath string) {
 	form := tview.NewForm()
-	form.AddInputField("Name", "", 20, nil, nil)
-	form.AddInputField("Command", "", 50, nil, nil)
-	form.AddDropDown("Type", []string{"alias", "function"}, 0, nil)
-	form.AddButton("Save", func() {
-		name := form.GetFormItem(0).(*tview.InputField).GetText()
-		command := form.GetFormItem(1).(*tview.InputField).GetText()
-		_, aliasType := form.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
-
-		if name == "" || command == "" {
-			showErrorModal(app, pages, "Both fields are required")
-			return
+	form.AddInputField("Name", alias.Name, 20, nil, nil)
+	form.AddDropDown("Type", types, typeIndex, nil)
+	form.AddInputField("Group", alias.Group, 20, nil, nil)
+	form.AddInputField("Description", alias.Description, 60, nil, nil)
+	form.AddInputField("Tags", strings.Join(alias.Tags, ", "), 40, nil, nil)
+
+	// The command field is the last form item and is swapped between a
+	// single-line input and a text area when the type changes.
+	setCommandField := func(aliasType, command string) {
+		if i := form.GetFormItemIndex("Command"); i >= 0 {
+			form.RemoveFormItem(i)
 		}
-
-		err := appendAlias(aliasFilePath, name, command, aliasType)
-		if err != nil {
-			showErrorModal(app, pages, "Error adding alias/function: "+err.Error())
+		if aliasType == "function" {
+			form.AddTextArea("Command", command, 60, 10, 0, nil)
 		} else {
-			pages.SwitchToPage("aliasManagement")
+			form.AddInputField("Command", command, 50, nil, nil)
 		}
-	}).
-		AddButton("Cancel", func() {
-			pages.SwitchToPage("aliasManagement")
-		})
-
-	form.SetBorder(true).SetTitle("Add Alias/Function").SetTitleAlign(tview.AlignCenter)
-	form.SetButtonsAlign(tview.AlignCenter)
-
-	pages.AddPage("addAlias", form, true, true)
-	pages.SwitchToPage("addAlias")
+	}
+	commandText := func() string {
+		switch field := form.GetFormItemByLabel("Command").(type) {
+		case *tview.TextArea:
+			return field.GetText()
+		case *tview.InputField:
+			return field.GetText()
+		}
+		return ""
+	}
+	inputText := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+	current := func() Alias {
+		_, aliasType := form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+		return Alias{
+			Name:        inputText("Name"),
+			Command:     commandText(),
+			Type:        aliasType,
+			Group:       inputText("Group"),
+			Description: inputText("Description"),
+			Tags:        parseTags(inputText("Tags")),
+		}
+	}
+	saveAlias := func(alias Alias) {
+		if err := save(alias); err != nil {
+			showErrorModalFor(app, pages, "Error saving alias/function: "+err.Error(), "aliasForm")
+		} else {
+			back()
+		}
+	}
 
-	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyRune && (event.Rune() == 'q' || event.Rune() == 'Q') {
-			pages.SwitchToPage("aliasManagement")
-			app.SetInputCapture(nil)
-			return nil
+	setCommandField(alias.Type, alias.Command)
+	form.GetFormItemByLabel("Type").(*tview.DropDown).SetSelectedFunc(func(aliasType string, _ int) {
+		_, isTextArea := form.GetFormItemByLabel("Command").(*tview.TextArea)
+		if isTextArea != (aliasType == "function") {
+			setCommandField(aliasType, commandText())
 		}
-		return event
 	})
-}
-
-func showErrorModal(app *tview.Application, pages *tview.Pages, message string) {
-	modal := tview.NewModal().
-		SetText(message).
-		AddButtons([]string{"OK"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			pages.SwitchToPage("aliasManagement")
-		})
 
-	pages.AddPage("errorModal", modal, false, true)
-	pages.SwitchToPage("errorModal")
-}
+	form.AddButton("Save", func() {
+		alias := current()
+		if alias.Name == "" || alias.Command == "" {
+			showErrorModalFor(app, pages, "The name and the command are required", "aliasForm")
+			return
+		}
+		saveAlias(alias)
+	}).
+		AddButton("Open in $EDITOR", func() {
+			// The editor only sees the definition; the group, description
+			// and tags are taken from the form.
+			form := current()
+			editAliasInEditor(app, pages, editorTemplate(form), func(alias Alias) {
+				alias.Group, alias.Description, alias.Tags = form.Group, form.Description, form.Tags
+				saveAlias(alias)
+			})
+		}).
+		AddButton("Cancel", back)
+
+	form.SetBorder(true).SetTitle(title).SetTitleAlign(tview.AlignCenter)
+	form.SetButtonsAlign(tview.AlignCenter)
+	form.SetCancelFunc(back)
 
-type Alias struct {
-	Name    string
-	Command string
-	Type    string // "alias" or "function"
+	pages.AddPage("aliasForm", form, true, true)
+	pages.SwitchToPage("aliasForm")
+	app.SetInputCapture(nil)
 }
 
-func readAliases(aliasFilePath string) ([]Alias, error) {
-	content, err := os.ReadFile(aliasFilePath)
+// editAliasInEditor suspends the TUI to edit text in the user's editor and
+// passes the resulting definition to save. Text that does not parse can be
+// edited again without losing the changes.
+func editAliasInEditor(app *tview.Application, pages *tview.Pages, text string, save func(Alias)) {
+	var edited string
+	var err error
+	app.Suspend(func() {
+		edited, err = editInEditor(text)
+	})
 	if err != nil {
-		return nil, err
+		showErrorModalFor(app, pages, "Error running editor: "+err.Error(), "aliasForm")
+		return
 	}
 
-	lines := strings.Split(string(content), "\n")
-	aliases := []Alias{}
-	inFunction := false
-	currentFunction := Alias{}
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "alias ") {
-			parts := strings.SplitN(line[6:], "=", 2)
-			if len(parts) == 2 {
-				name := strings.TrimSpace(parts[0])
-				command := strings.Trim(strings.TrimSpace(parts[1]), "'\"")
-				aliases = append(aliases, Alias{Name: name, Command: command, Type: "alias"})
-			}
-		} else if strings.HasPrefix(line, "function ") || strings.HasSuffix(line, "() {") {
-			inFunction = true
-			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "function "), "() {"))
-			currentFunction = Alias{Name: name, Command: "", Type: "function"}
-		} else if inFunction {
-			if line == "}" {
-				inFunction = false
-				aliases = append(aliases, currentFunction)
-			} else {
-				currentFunction.Command += line + "\n"
-			}
-		}
+	alias, err := parseDefinition(edited)
+	if err == nil {
+		err = validateAlias(alias)
 	}
-
-	return aliases, nil
-}
-
-func appendAlias(aliasFilePath string, name, command, aliasType string) error {
-	f, err := os.OpenFile(aliasFilePath, os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
-		return err
+		modal := tview.NewModal().
+			SetText("The edited definition is not valid:\n\n" + err.Error()).
+			AddButtons([]string{"Edit again", "Discard"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				if buttonLabel == "Edit again" {
+					editAliasInEditor(app, pages, edited, save)
+				} else {
+					pages.SwitchToPage("aliasForm")
+				}
+			})
+		pages.AddPage("editorError", modal, false, true)
+		pages.SwitchToPage("editorError")
+		return
 	}
-	defer f.Close()
 
-	var aliasLine string
-	if aliasType == "alias" {
-		aliasLine = fmt.Sprintf("alias %s='%s'\n", name, command)
-	} else {
-		aliasLine = fmt.Sprintf("function %s() {\n%s\n}\n", name, command)
-	}
-	_, err = f.WriteString(aliasLine)
-	return err
+	save(alias)
 }
 
-func removeAlias(aliasFilePath, name string) error {
-	content, err := os.ReadFile(aliasFilePath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	newLines := []string{}
+func showErrorModal(app *tview.Application, pages *tview.Pages, message string) {
+	showErrorModalFor(app, pages, message, "aliasManagement")
+}
 
-	for _, line := range lines {
-		if !strings.HasPrefix(line, fmt.Sprintf("alias %s=", name)) {
-			newLines = append(newLines, line)
-		}
-	}
+// showErrorModalFor shows an error and returns to returnPage once dismissed.
+func showErrorModalFor(app *tview.Application, pages *tview.Pages, message, returnPage string) {
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.SwitchToPage(returnPage)
+		})
 
-	return os.WriteFile(aliasFilePath, []byte(strings.Join(newLines, "\n")), 0644)
+	pages.AddPage("errorModal", modal, false, true)
+	pages.SwitchToPage("errorModal")
 }
 
-func detectShellConfig(homeDir string) string {
-	shells := []string{".bashrc", ".zshrc", ".bash_profile"}
-	for _, shell := range shells {
-		path := filepath.Join(homeDir, shell)
-		if _, err := os.Stat(path); err == nil {
-			return path
-		}
-	}
-	return ""
+type Alias struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // "alias", "function", or the zsh-only "global" and "suffix"
+	Command     string   `json:"command"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Group       string   `json:"group,omitempty"`
+	// Disabled definitions stay in the store but are left out of the
+	// generated files.
+	Disabled bool `json:"disabled,omitempty"`
+	// Created, Modified and Author are kept by the store; they are nil and
+	// empty for definitions made before they were.
+	Created  *time.Time `json:"created,omitempty"`
+	Modified *time.Time `json:"modified,omitempty"`
+	Author   string     `json:"author,omitempty"`
+	// Line is where the definition starts in the generated alias file, or
+	// in the file it was parsed from.
+	Line int `json:"-"`
 }
 
-func showAIAssistedAliasCreation(app *tview.Application, pages *tview.Pages, aliasFilePath string) {
+func showAIAssistedAliasCreation(app *tview.Application, pages *tview.Pages, paths appPaths) {
 	if !isLLMAvailable() {
 		showErrorModal(app, pages, "The 'llm' command is not available on your system. Install it: https://llm.datasette.io/en/stable/")
 		return
@@ -411,7 +885,7 @@ func showAIAssistedAliasCreation(app *tview.Application, pages *tview.Pages, ali
 			showErrorModal(app, pages, "Please enter a description.")
 			return
 		}
-		generateAIAssistedAliasOrFunction(app, pages, aliasFilePath, typeStr, description)
+		generateAIAssistedAliasOrFunction(app, pages, paths, typeStr, description)
 	}).
 		AddButton("Cancel", func() {
 			pages.SwitchToPage("main")
@@ -424,12 +898,13 @@ func showAIAssistedAliasCreation(app *tview.Application, pages *tview.Pages, ali
 	pages.SwitchToPage("aiAssistedCreation")
 }
 
-func generateAIAssistedAliasOrFunction(app *tview.Application, pages *tview.Pages, aliasFilePath, typeStr, description string) {
-	config, err := readConfig(aliasFilePath)
+func generateAIAssistedAliasOrFunction(app *tview.Application, pages *tview.Pages, paths appPaths, typeStr, description string) {
+	store, err := loadAliasStore(paths.Store)
 	if err != nil {
 		showErrorModal(app, pages, fmt.Sprintf("Error reading configuration: %v", err))
 		return
 	}
+	config := store.Config()
 
 	var prompt string
 	if typeStr == "Alias" {
@@ -451,7 +926,7 @@ func generateAIAssistedAliasOrFunction(app *tview.Application, pages *tview.Page
 		return
 	}
 
-	showAliasOrFunctionConfirmation(app, pages, aliasFilePath, result)
+	showAliasOrFunctionConfirmation(app, pages, paths, result)
 }
 
 func extractAliasOrFunctionFromOutput(output string) string {
@@ -463,31 +938,22 @@ func extractAliasOrFunctionFromOutput(output string) string {
 	return ""
 }
 
-func showAliasOrFunctionConfirmation(app *tview.Application, pages *tview.Pages, aliasFilePath, result string) {
-	isFunction := strings.HasPrefix(result, "function")
-	typeStr := "alias"
-	if isFunction {
-		typeStr = "function"
+func showAliasOrFunctionConfirmation(app *tview.Application, pages *tview.Pages, paths appPaths, result string) {
+	alias, err := parseDefinition(result)
+	if err != nil {
+		showAIOutput(app, pages, result)
+		return
 	}
+	typeStr := alias.Type
 
 	modal := tview.NewModal().
 		SetText(fmt.Sprintf("Do you want to add this %s?\n\n%s", typeStr, result)).
 		AddButtons([]string{"Add", "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			if buttonLabel == "Add" {
-				var name, command string
-				if isFunction {
-					parts := strings.SplitN(result, "{", 2)
-					name = strings.TrimSpace(strings.TrimPrefix(parts[0], "function"))
-					name = strings.TrimSuffix(name, "()")
-					command = "{" + parts[1]
-				} else {
-					parts := strings.SplitN(result, "=", 2)
-					name = strings.TrimPrefix(strings.TrimSpace(parts[0]), "alias ")
-					command = strings.Trim(strings.TrimSpace(parts[1]), "'")
-				}
-
-				err := appendAlias(aliasFilePath, name, command, typeStr)
+				err := editAliasStore(paths, "add "+alias.Name, func(store *AliasStore) error {
+					return store.Add(alias)
+				})
 				if err != nil {
 					showErrorModal(app, pages, fmt.Sprintf("Error adding %s: %v", typeStr, err))
 				} else {
@@ -532,114 +998,280 @@ func isLLMAvailable() bool {
 
 type Config struct {
 	Model string `json:"model"`
+	// AliasFile is the generated script that the managed block sources. It
+	// is empty while aliasman is not installed.
+	AliasFile string   `json:"alias_file,omitempty"`
+	RCFiles   []string `json:"rc_files,omitempty"` // shell config files containing the managed block, and generated scripts
+	// FishAbbr writes aliases to the fish script as abbreviations.
+	FishAbbr bool `json:"fish_abbr,omitempty"`
+	// GitHistory commits every change to the store to a git repository.
+	GitHistory bool `json:"git_history,omitempty"`
 }
 
-func readConfig(aliasFilePath string) (Config, error) {
-	content, err := os.ReadFile(aliasFilePath)
-	if err != nil {
-		return Config{}, err
-	}
+func showSettings(app *tview.Application, pages *tview.Pages, paths appPaths, homeDir string) {
+	list := tview.NewList().
+		AddItem("Check Installation", "Check if Aliasman is installed", 'c', nil).
+		AddItem("Change LLM Model", "Modify the AI model used for alias generation", 'm', nil).
+		AddItem("Fish Abbreviations", "Toggle writing fish aliases as abbreviations", 'f', nil).
+		AddItem("Git History", "Toggle committing every change to a git repository", 'g', nil).
+		AddItem("Backups", "Preview and restore earlier versions of your aliases", 'b', nil).
+		AddItem("Uninstall", "Remove Aliasman from your shell config files", 'u', nil).
+		AddItem("Back", "Return to main menu", 'q', nil)
 
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "# {") && strings.HasSuffix(line, "}") {
-			var config Config
-			err := json.Unmarshal([]byte(line[2:]), &config)
-			if err == nil {
-				return config, nil
-			}
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		switch index {
+		case 0:
+			checkInstallation(app, pages, paths, homeDir)
+		case 1:
+			changeLLMModel(app, pages, paths)
+		case 2:
+			toggleFishAbbreviations(app, pages, paths)
+		case 3:
+			toggleGitHistory(app, pages, paths)
+		case 4:
+			showBackups(app, pages, paths)
+		case 5:
+			showUninstall(app, pages, paths, homeDir)
+		case 6:
+			pages.SwitchToPage("main")
 		}
-	}
+	})
 
-	return Config{Model: "llama3:8b"}, nil // Default model if not found
+	pages.AddPage("settings", list, true, true)
+	pages.SwitchToPage("settings")
 }
 
-func listAliasesCli() {
-	aliases, functions, err := loadAliasesAndFunctions()
+func toggleFishAbbreviations(app *tview.Application, pages *tview.Pages, paths appPaths) {
+	var enabled bool
+	err := editAliasStore(paths, "toggle fish abbreviations", func(store *AliasStore) error {
+		config := store.Config()
+		config.FishAbbr = !config.FishAbbr
+		enabled = config.FishAbbr
+		return store.SetConfig(config)
+	})
 	if err != nil {
-		fmt.Println("Error loading aliases and functions:", err)
+		showErrorModalFor(app, pages, fmt.Sprintf("Error updating configuration: %v", err), "settings")
 		return
 	}
 
-	fmt.Println("Available aliases:")
-	for name, command := range aliases {
-		fmt.Printf("  %s: %s\n", name, command)
+	message := "Fish aliases are now written as aliases."
+	if enabled {
+		message = "Fish aliases are now written as abbreviations."
 	}
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.SwitchToPage("settings")
+		})
+	pages.AddPage("modal", modal, false, true)
+	pages.SwitchToPage("modal")
+}
 
-	fmt.Println("\nAvailable functions:")
-	for name := range functions {
-		fmt.Printf("  %s\n", name)
+func toggleGitHistory(app *tview.Application, pages *tview.Pages, paths appPaths) {
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		showErrorModalFor(app, pages, fmt.Sprintf("Error loading configuration: %v", err), "settings")
+		return
 	}
-}
 
-func loadAliasesAndFunctions() (map[string]string, map[string]bool, error) {
-	homeDir, err := os.UserHomeDir()
+	message := "Changes are no longer committed to git."
+	if store.Config().GitHistory {
+		err = disableGitHistory(paths)
+	} else {
+		err = enableGitHistory(paths)
+		message = fmt.Sprintf("Every change is now committed to the git repository in %s.\nSee it with aliasman log and aliasman diff.", filepath.Dir(paths.Store))
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("error getting home directory: %w", err)
+		showErrorModalFor(app, pages, fmt.Sprintf("Error updating configuration: %v", err), "settings")
+		return
 	}
 
-	aliasFilePath := filepath.Join(homeDir, aliasFileName)
-	content, err := os.ReadFile(aliasFilePath)
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.SwitchToPage("settings")
+		})
+	pages.AddPage("modal", modal, false, true)
+	pages.SwitchToPage("modal")
+}
+
+func showUninstall(app *tview.Application, pages *tview.Pages, paths appPaths, homeDir string) {
+	changes, err := planUninstall(paths, homeDir)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading alias file: %w", err)
+		showErrorModalFor(app, pages, "Error reading shell config files: "+err.Error(), "settings")
+		return
 	}
 
-	aliases := make(map[string]string)
-	functions := make(map[string]bool)
-	lines := strings.Split(string(content), "\n")
+	diff := coloredDiff(changes)
+	if len(changes) == 0 {
+		diff = "No shell config file contains the Aliasman managed block."
+	}
+
+	diffView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(diff)
+
+	archive := false
+	form := tview.NewForm()
+	form.AddCheckbox("Archive "+paths.Store, false, func(checked bool) {
+		archive = checked
+	})
+	form.AddButton("Uninstall", func() {
+		archivePath, err := uninstallAliasman(paths, changes, archive)
+		if err != nil {
+			showErrorModalFor(app, pages, "Error uninstalling: "+err.Error(), "uninstall")
+			return
+		}
+
+		message := "Aliasman has been removed from your shell config files. Open a new shell for the change to take effect."
+		if archivePath != "" {
+			message += "\n\nYour aliases were archived to " + archivePath
+		}
+
+		modal := tview.NewModal().
+			SetText(message).
+			AddButtons([]string{"Quit"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				app.Stop()
+			})
+		pages.AddPage("uninstalled", modal, false, true)
+		pages.SwitchToPage("uninstalled")
+	})
+	form.AddButton("Cancel", func() {
+		pages.SwitchToPage("settings")
+	})
+	form.SetCancelFunc(func() {
+		pages.SwitchToPage("settings")
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(diffView, 0, 1, false).
+		AddItem(form, 5, 0, true)
 
-	inFunction := false
-	currentFunction := ""
+	frame := tview.NewFrame(flex).SetBorders(0, 0, 0, 0, 0, 0)
+	frame.AddText("Uninstall Aliasman (changes to be applied)", true, tview.AlignCenter, tcell.ColorYellow)
+
+	pages.AddPage("uninstall", frame, true, true)
+	pages.SwitchToPage("uninstall")
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "alias ") {
-			parts := strings.SplitN(line[6:], "=", 2)
-			if len(parts) == 2 {
-				name := strings.TrimSpace(parts[0])
-				command := strings.Trim(strings.TrimSpace(parts[1]), "'\"")
-				aliases[name] = command
+// coloredDiff returns the diffs of changes with tview color tags.
+func coloredDiff(changes []fileChange) string {
+	var diff strings.Builder
+	for _, c := range changes {
+		for _, line := range strings.SplitAfter(c.Diff(), "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				diff.WriteString("[teal]" + tview.Escape(line) + "[-]")
+			case strings.HasPrefix(line, "-"):
+				diff.WriteString("[red]" + tview.Escape(line) + "[-]")
+			case strings.HasPrefix(line, "+"):
+				diff.WriteString("[green]" + tview.Escape(line) + "[-]")
+			default:
+				diff.WriteString(tview.Escape(line))
 			}
-		} else if strings.HasPrefix(line, "function ") || strings.HasSuffix(line, "() {") {
-			inFunction = true
-			currentFunction = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "function "), "() {"))
-			functions[currentFunction] = true
-		} else if line == "}" && inFunction {
-			inFunction = false
-			currentFunction = ""
 		}
 	}
-
-	return aliases, functions, nil
+	return diff.String()
 }
 
-func showSettings(app *tview.Application, pages *tview.Pages, aliasFilePath, shellConfigPath string) {
-	list := tview.NewList().
-		AddItem("Check Installation", "Check if Aliasman is installed", 'c', nil).
-		AddItem("Change LLM Model", "Modify the AI model used for alias generation", 'm', nil).
-		AddItem("Back", "Return to main menu", 'q', nil)
+func showBackups(app *tview.Application, pages *tview.Pages, paths appPaths) {
+	backups, err := listBackups(paths.StateDir)
+	if err != nil {
+		showErrorModalFor(app, pages, "Error reading backups: "+err.Error(), "settings")
+		return
+	}
+	if len(backups) == 0 {
+		showErrorModalFor(app, pages, "There are no backups yet. One is made before every change.", "settings")
+		return
+	}
 
+	list := tview.NewList()
+	for _, b := range backups {
+		list.AddItem(b.Time.Local().Format("2006-01-02 15:04:05")+"  "+b.Reason, "Backup "+b.ID, 0, nil)
+	}
+	list.AddItem("Back", "Return to settings", 'q', nil)
 	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
-		switch index {
-		case 0:
-			checkInstallation(app, pages, aliasFilePath, shellConfigPath)
-		case 1:
-			changeLLMModel(app, pages, aliasFilePath)
-		case 2:
-			pages.SwitchToPage("main")
+		if index == len(backups) {
+			pages.SwitchToPage("settings")
+			return
 		}
+		showBackup(app, pages, paths, backups[index])
+	})
+	list.SetDoneFunc(func() {
+		pages.SwitchToPage("settings")
 	})
 
-	pages.AddPage("settings", list, true, true)
-	pages.SwitchToPage("settings")
+	frame := tview.NewFrame(list).SetBorders(0, 0, 0, 0, 0, 0)
+	frame.AddText("Backups (newest first)", true, tview.AlignCenter, tcell.ColorYellow)
+
+	pages.AddPage("backups", frame, true, true)
+	pages.SwitchToPage("backups")
+}
+
+// showBackup previews what restoring a backup would change and restores it
+// on request.
+func showBackup(app *tview.Application, pages *tview.Pages, paths appPaths, b backup) {
+	changes, err := restoreChanges(paths.StateDir, b)
+	if err != nil {
+		showErrorModalFor(app, pages, "Error reading backup: "+err.Error(), "backups")
+		return
+	}
+
+	diff := coloredDiff(changes)
+	if len(changes) == 0 {
+		diff = "The files already match this backup."
+	}
+	diffView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(diff)
+
+	form := tview.NewForm()
+	if len(changes) > 0 {
+		form.AddButton("Restore", func() {
+			if err := restoreBackup(paths, b); err != nil {
+				showErrorModalFor(app, pages, "Error restoring backup: "+err.Error(), "backup")
+				return
+			}
+			modal := tview.NewModal().
+				SetText("Backup " + b.ID + " has been restored. Run aliasman-reload in open shells to pick up the change.").
+				AddButtons([]string{"OK"}).
+				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+					pages.SwitchToPage("settings")
+				})
+			pages.AddPage("modal", modal, false, true)
+			pages.SwitchToPage("modal")
+		})
+	}
+	form.AddButton("Back", func() {
+		pages.SwitchToPage("backups")
+	})
+	form.SetCancelFunc(func() {
+		pages.SwitchToPage("backups")
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(diffView, 0, 1, false).
+		AddItem(form, 3, 0, true)
+
+	frame := tview.NewFrame(flex).SetBorders(0, 0, 0, 0, 0, 0)
+	frame.AddText(fmt.Sprintf("Restore backup %s: %s", b.ID, b.Reason), true, tview.AlignCenter, tcell.ColorYellow)
+
+	pages.AddPage("backup", frame, true, true)
+	pages.SwitchToPage("backup")
 }
 
-func changeLLMModel(app *tview.Application, pages *tview.Pages, aliasFilePath string) {
-	config, err := readConfig(aliasFilePath)
+func changeLLMModel(app *tview.Application, pages *tview.Pages, paths appPaths) {
+	store, err := loadAliasStore(paths.Store)
 	if err != nil {
 		showErrorModal(app, pages, fmt.Sprintf("Error reading configuration: %v", err))
 		return
 	}
+	config := store.Config()
 
 	// Run "llm models" command
 	cmd := exec.Command("llm", "models")
@@ -673,8 +1305,13 @@ func changeLLMModel(app *tview.Application, pages *tview.Pages, aliasFilePath st
 			return
 		}
 
-		config.Model = newModel
-		err := updateConfig(aliasFilePath, config)
+		// The settings are read again under the lock, so that changes made
+		// by other processes while the form was open are kept.
+		err := editAliasStore(paths, "change the LLM model", func(store *AliasStore) error {
+			config := store.Config()
+			config.Model = newModel
+			return store.SetConfig(config)
+		})
 		if err != nil {
 			showErrorModal(app, pages, fmt.Sprintf("Error updating configuration: %v", err))
 		} else {
@@ -704,28 +1341,3 @@ func changeLLMModel(app *tview.Application, pages *tview.Pages, aliasFilePath st
 		return event
 	})
 }
-
-func updateConfig(aliasFilePath string, config Config) error {
-	content, err := os.ReadFile(aliasFilePath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	configJSON, err := json.Marshal(config)
-	if err != nil {
-		return err
-	}
-
-	newConfigLine := fmt.Sprintf("# %s", string(configJSON))
-	updatedLines := []string{newConfigLine}
-
-	for i, line := range lines {
-		if i == 0 && strings.HasPrefix(line, "# {") && strings.HasSuffix(line, "}") {
-			continue
-		}
-		updatedLines = append(updatedLines, line)
-	}
-
-	return os.WriteFile(aliasFilePath, []byte(strings.Join(updatedLines, "\n")), 0644)
-}
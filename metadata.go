@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+	"time"
+)
+
+// timeLayout is how the metadata timestamps are shown.
+const timeLayout = "2006-01-02 15:04"
+
+// parseTags splits a list of tags separated by commas or spaces, dropping
+// empty and repeated ones.
+func parseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// validateMetadata checks the description, tags and group of alias, which
+// must fit on one line of the list table.
+func validateMetadata(alias Alias) error {
+	if err := validateGroup(alias.Group); err != nil {
+		return err
+	}
+	if strings.ContainsAny(alias.Description, "\r\n") {
+		return fmt.Errorf("the description of %q must be a single line", alias.Name)
+	}
+	for _, tag := range alias.Tags {
+		if tag == "" || strings.ContainsAny(tag, ", \t\r\n") {
+			return fmt.Errorf("%q is not a valid tag", tag)
+		}
+	}
+	return nil
+}
+
+// sameDefinition reports whether a and b differ in nothing but their
+// timestamps and author.
+func sameDefinition(a, b Alias) bool {
+	return a.Name == b.Name && a.Type == b.Type && a.Command == b.Command &&
+		a.Description == b.Description && slices.Equal(a.Tags, b.Tags) &&
+		a.Group == b.Group && a.Disabled == b.Disabled
+}
+
+// currentAuthor returns the name recorded as the author of new definitions.
+func currentAuthor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// formatTime returns t in timeLayout, or "" for definitions made before
+// timestamps were kept.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(timeLayout)
+}
+
+// aliasHistory describes when and by whom alias was created and last
+// modified, as far as that is known.
+func aliasHistory(alias Alias) string {
+	var parts []string
+	if alias.Created != nil {
+		created := "created " + formatTime(alias.Created)
+		if alias.Author != "" {
+			created += " by " + alias.Author
+		}
+		parts = append(parts, created)
+	}
+	if alias.Modified != nil && (alias.Created == nil || !alias.Modified.Equal(*alias.Created)) {
+		parts = append(parts, "modified "+formatTime(alias.Modified))
+	}
+	return strings.Join(parts, ", ")
+}
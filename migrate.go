@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// migrateAliasFile imports an alias file written by an older version, which
+// held the definitions and a "# {json}" settings comment, into a new store at
+// storePath. The old file is kept with a ".pre-migration" suffix and replaced
+// by the generated one, so the shell configs that source it keep working.
+// It does nothing once the store exists, and returns what could not be
+// imported.
+func migrateAliasFile(storePath, aliasFilePath string) ([]string, error) {
+	if fileExists(storePath) || !fileExists(aliasFilePath) || isGeneratedFile(aliasFilePath) {
+		return nil, nil
+	}
+	unlock, err := lockStore(storePath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if fileExists(storePath) {
+		// Another aliasman process got here first.
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(aliasFilePath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parseAliasFile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("cannot import %s: %w", aliasFilePath, err)
+	}
+
+	backupPath := aliasFilePath + ".pre-migration"
+	var notes []string
+	store := &AliasStore{path: storePath}
+	// Lines that mix definitions with other commands, such as
+	// "[[ -n $X ]] && alias a=b", are not imported at all. The comment lines
+	// directly above a definition describe it, as removing it takes them
+	// along; other comments are reported.
+	var aliases []Alias
+	var comments []*Node
+	dropComments := func() {
+		for _, c := range comments {
+			notes = append(notes, fmt.Sprintf("line %d is a comment that does not describe a definition; it is only kept in %s", c.Line, backupPath))
+		}
+		comments = nil
+	}
+	for _, n := range file.Nodes {
+		switch n.Kind {
+		case NodeComment:
+			if !isLegacyConfig(n) {
+				comments = append(comments, n)
+			}
+			continue
+		case NodeOther:
+			notes = append(notes, fmt.Sprintf("line %d is not an alias or function definition; it is only kept in %s", n.Line, backupPath))
+		case NodeAlias, NodeFunction:
+			if len(comments) > 0 && len(n.Aliases) == 1 {
+				alias := n.Aliases[0]
+				alias.Description = commentText(comments)
+				aliases = append(aliases, alias)
+				comments = nil
+				continue
+			}
+			aliases = append(aliases, n.Aliases...)
+		}
+		dropComments()
+	}
+	dropComments()
+	lines := map[string]int{}
+	for _, alias := range aliases {
+		if line, ok := lines[alias.Name]; ok {
+			// The shell uses the last definition of a name.
+			notes = append(notes, fmt.Sprintf("line %d: %q is defined again; the definition on line %d was dropped", alias.Line, alias.Name, line))
+			store.Remove(alias.Name)
+		}
+		if err := store.Add(alias); err != nil {
+			notes = append(notes, fmt.Sprintf("line %d: %v; it is only kept in %s", alias.Line, err, backupPath))
+			continue
+		}
+		lines[alias.Name] = alias.Line
+	}
+
+	config := legacyConfig(file)
+	config.AliasFile = aliasFilePath
+	store.SetConfig(config)
+
+	if err := os.Rename(aliasFilePath, backupPath); err != nil {
+		return nil, err
+	}
+	if err := store.Save(); err != nil {
+		os.Remove(storePath)
+		os.Rename(backupPath, aliasFilePath)
+		return nil, err
+	}
+	return notes, nil
+}
+
+// legacyConfig returns the settings from the "# {json}" comment of an old
+// alias file.
+func legacyConfig(file *AliasFile) Config {
+	for _, n := range file.Nodes {
+		var config Config
+		if isLegacyConfig(n) && json.Unmarshal([]byte(strings.TrimSpace(n.Raw)[2:]), &config) == nil {
+			return config
+		}
+	}
+	return Config{Model: defaultModel}
+}
+
+// isLegacyConfig reports whether n is the "# {json}" settings comment.
+func isLegacyConfig(n *Node) bool {
+	line := strings.TrimSpace(n.Raw)
+	return n.Kind == NodeComment && strings.HasPrefix(line, "# {") && strings.HasSuffix(line, "}")
+}
+
+// commentText joins comment lines into a one-line description.
+func commentText(comments []*Node) string {
+	var words []string
+	for _, c := range comments {
+		words = append(words, strings.Fields(strings.TrimLeft(strings.TrimSpace(c.Raw), "#"))...)
+	}
+	return strings.Join(words, " ")
+}
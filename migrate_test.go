@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrateAliasFile(t *testing.T) {
+	type imported struct {
+		Name, Type, Command, Description string
+	}
+	tests := []struct {
+		name    string
+		src     string
+		aliases []imported
+		model   string
+		notes   []string // the start of each note, after "line "
+	}{
+		{
+			name: "definitions and settings",
+			src: "# {\"model\":\"mistral\"}\n" +
+				"alias ll='ls -l'\n" +
+				"greet() {\n  echo hi\n}\n",
+			aliases: []imported{
+				{"ll", "alias", "ls -l", ""},
+				{"greet", "function", "  echo hi", ""},
+			},
+			model: "mistral",
+		},
+		{
+			name: "comments above a definition describe it",
+			src: "# List files\n# in long format\nalias ll='ls -l'\n" +
+				"\n# Say hello\ngreet() { echo hi; }\n",
+			aliases: []imported{
+				{"ll", "alias", "ls -l", "List files in long format"},
+				{"greet", "function", "echo hi;", "Say hello"},
+			},
+			model: defaultModel,
+		},
+		{
+			name: "other comments are reported",
+			src: "#!/bin/sh\n\n# separated by a blank line\n\nalias ll='ls -l'\n" +
+				"# above two aliases\nalias a=b c=d\n",
+			aliases: []imported{
+				{"ll", "alias", "ls -l", ""},
+				{"a", "alias", "b", ""},
+				{"c", "alias", "d", ""},
+			},
+			model: defaultModel,
+			notes: []string{"1 is a comment", "3 is a comment", "6 is a comment"},
+		},
+		{
+			name: "other commands are reported",
+			src: "export EDITOR=vim\n# not imported\n[[ -n $X ]] && alias x=y\n" +
+				"alias a=1\nalias a=2\n",
+			aliases: []imported{{"a", "alias", "2", ""}},
+			model:   defaultModel,
+			notes:   []string{"1 is not", "3 is not", "2 is a comment", `5: "a" is defined again`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			storePath := filepath.Join(dir, storeFileName)
+			aliasFile := filepath.Join(dir, aliasFileName)
+			if err := os.WriteFile(aliasFile, []byte(tt.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			notes, err := migrateAliasFile(storePath, aliasFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(notes) != len(tt.notes) {
+				t.Fatalf("notes = %q, want %d", notes, len(tt.notes))
+			}
+			for i, note := range notes {
+				if !strings.HasPrefix(note, "line "+tt.notes[i]) {
+					t.Errorf("note %d = %q, want it to start with %q", i, note, "line "+tt.notes[i])
+				}
+			}
+
+			store, err := loadAliasStore(storePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []imported
+			for _, alias := range store.Aliases() {
+				got = append(got, imported{alias.Name, alias.Type, alias.Command, alias.Description})
+			}
+			if !reflect.DeepEqual(got, tt.aliases) {
+				t.Errorf("aliases = %+v, want %+v", got, tt.aliases)
+			}
+			if config := store.Config(); config.Model != tt.model || config.AliasFile != aliasFile {
+				t.Errorf("settings = %+v, want model %q and alias file %s", config, tt.model, aliasFile)
+			}
+
+			if backup, err := os.ReadFile(aliasFile + ".pre-migration"); err != nil || string(backup) != tt.src {
+				t.Errorf("backup = %q, %v; want the original file", backup, err)
+			}
+			if !isGeneratedFile(aliasFile) {
+				t.Error("the alias file was not replaced by a generated one")
+			}
+
+			// Once the store exists, nothing is imported again.
+			if notes, err := migrateAliasFile(storePath, aliasFile); notes != nil || err != nil {
+				t.Errorf("second migration = %q, %v", notes, err)
+			}
+		})
+	}
+}
+
+func TestMigrateAliasFileUnparsable(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, storeFileName)
+	aliasFile := filepath.Join(dir, aliasFileName)
+	src := "alias ll='ls -l\n"
+	if err := os.WriteFile(aliasFile, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrateAliasFile(storePath, aliasFile); err == nil {
+		t.Fatal("migrating an unparsable file succeeded")
+	}
+	if fileExists(storePath) {
+		t.Error("a store was created")
+	}
+	if content, _ := os.ReadFile(aliasFile); string(content) != src {
+		t.Errorf("the alias file was changed to %q", content)
+	}
+}
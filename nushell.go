@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// nuNamePattern matches the command names that need no quoting in nushell.
+	nuNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	// nuPlainCommand matches a command made of plain words only, which reads
+	// the same in nushell as in bash.
+	nuPlainCommand = regexp.MustCompile(`^[A-Za-z0-9_./~:@%+,=-]+( +[A-Za-z0-9_./~:@%+,=-]+)*$`)
+	// nuStateChange matches commands that change the state of the calling
+	// shell, which cannot work from the child shell a def runs them in.
+	nuStateChange = regexp.MustCompile(`(?m)(^|[;&|(]\s*)(cd|export|source|\.|alias|unset|set)\s`)
+)
+
+// nushellConfigPath returns where the nushell script is generated. Nushell
+// sources every file in its autoload directory on startup.
+func nushellConfigPath(homeDir string) string {
+	return filepath.Join(configHome(homeDir), "nushell", "autoload", "aliasman.nu")
+}
+
+// renderNushell renders aliases made of plain words as nushell aliases of the
+// external command. Anything else becomes a def that hands the command to
+// sh, so it has to be valid POSIX sh.
+func renderNushell(alias Alias, _ Config) (string, error) {
+	if alias.Name == reloadAliasName {
+		return "", fmt.Errorf("nushell reads its config when it starts and cannot reload it")
+	}
+	if alias.Type == "suffix" {
+		return "", fmt.Errorf("suffix aliases are only supported by zsh")
+	}
+	if !nuNamePattern.MatchString(alias.Name) {
+		return "", fmt.Errorf("%q is not a valid nushell command name", alias.Name)
+	}
+
+	if alias.Type != "function" && nuPlainCommand.MatchString(alias.Command) {
+		command := alias.Command
+		if !strings.HasPrefix(command, "cd ") && command != "cd" {
+			// "^" runs the external command rather than a nushell builtin of
+			// the same name, such as ls.
+			command = "^" + command
+		}
+		return fmt.Sprintf("alias %s = %s\n", alias.Name, command), nil
+	}
+	if alias.Type == "global" {
+		return "", fmt.Errorf("global aliases are only supported by zsh")
+	}
+
+	if problems := unsupportedSyntax(alias.Command, posixSyntaxRules); len(problems) > 0 {
+		return "", fmt.Errorf("uses bash syntax sh does not support: %s", strings.Join(problems, ", "))
+	}
+	if nuStateChange.MatchString(alias.Command) {
+		return "", fmt.Errorf("changes the state of the shell, which a nushell def cannot do")
+	}
+
+	script := alias.Command
+	if alias.Type == "alias" {
+		script += ` "$@"`
+	}
+	return fmt.Sprintf("def --wrapped %s [...args] {\n    ^sh -c %s %s ...$args\n}\n", alias.Name, nuQuote(script), alias.Name), nil
+}
+
+// nuQuote quotes s as a nushell string literal without escapes: a single
+// quoted string, or a raw string when s contains single quotes.
+func nuQuote(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	hashes := "#"
+	for strings.Contains(s, "'"+hashes) {
+		hashes += "#"
+	}
+	return "r" + hashes + "'" + s + "'" + hashes
+}
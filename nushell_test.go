@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderNushell(t *testing.T) {
+	tests := []struct {
+		name  string
+		alias Alias
+		want  string
+		err   string // a part of the error, if rendering fails
+	}{
+		{
+			name:  "plain alias",
+			alias: Alias{Name: "ll", Type: "alias", Command: "ls -l"},
+			want:  "alias ll = ^ls -l\n",
+		},
+		{
+			name:  "cd stays a builtin",
+			alias: Alias{Name: "up", Type: "alias", Command: "cd .."},
+			want:  "alias up = cd ..\n",
+		},
+		{
+			name:  "alias with shell syntax",
+			alias: Alias{Name: "count", Type: "alias", Command: "ls | wc -l"},
+			want:  "def --wrapped count [...args] {\n    ^sh -c 'ls | wc -l \"$@\"' count ...$args\n}\n",
+		},
+		{
+			name:  "function with single quotes",
+			alias: Alias{Name: "say", Type: "function", Command: "echo 'hi'"},
+			want:  "def --wrapped say [...args] {\n    ^sh -c r#'echo 'hi''# say ...$args\n}\n",
+		},
+		{
+			name:  "global alias of plain words",
+			alias: Alias{Name: "L", Type: "global", Command: "less"},
+			want:  "alias L = ^less\n",
+		},
+		{
+			name:  "global alias with shell syntax",
+			alias: Alias{Name: "G", Type: "global", Command: "| grep"},
+			err:   "only supported by zsh",
+		},
+		{
+			name:  "suffix alias",
+			alias: Alias{Name: "txt", Type: "suffix", Command: "less"},
+			err:   "only supported by zsh",
+		},
+		{
+			name:  "reload alias",
+			alias: Alias{Name: reloadAliasName, Type: "alias", Command: "source x"},
+			err:   "cannot reload",
+		},
+		{
+			name:  "invalid name",
+			alias: Alias{Name: "g.st", Type: "alias", Command: "git status"},
+			err:   "not a valid nushell command name",
+		},
+		{
+			name:  "state change",
+			alias: Alias{Name: "proj", Type: "function", Command: "cd ~/src && ls"},
+			err:   "changes the state of the shell",
+		},
+		{
+			name:  "bash syntax",
+			alias: Alias{Name: "t", Type: "function", Command: "[[ -f x ]] && cat x"},
+			err:   "[[ ... ]] tests",
+		},
+	}
+	for _, tt := range tests {
+		got, err := renderNushell(tt.alias, Config{})
+		switch {
+		case tt.err != "":
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: renderNushell = %q, %v; want an error containing %q", tt.name, got, err, tt.err)
+			}
+		case err != nil:
+			t.Errorf("%s: renderNushell: %v", tt.name, err)
+		case got != tt.want:
+			t.Errorf("%s: renderNushell = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNuQuote(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"ls -l", "'ls -l'"},
+		{"echo 'a'", "r#'echo 'a''#"},
+		{"echo 'a'#", "r##'echo 'a'#'##"},
+	}
+	for _, tt := range tests {
+		if got := nuQuote(tt.in); got != tt.want {
+			t.Errorf("nuQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,826 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NodeKind identifies what a top-level statement of the alias file contains.
+type NodeKind int
+
+const (
+	NodeBlank    NodeKind = iota // whitespace-only line
+	NodeComment                  // comment-only line
+	NodeAlias                    // one or more alias commands and nothing else
+	NodeFunction                 // a single function definition and nothing else
+	NodeOther                    // anything else, kept verbatim
+)
+
+// Node is one top-level statement of the alias file. Raw holds the exact
+// source text, including the trailing newline, so concatenating the Raw of
+// every node reproduces the original file byte for byte.
+type Node struct {
+	Kind    NodeKind
+	Raw     string
+	Line    int
+	Aliases []Alias
+}
+
+// AliasFile is the parsed form of an alias file.
+type AliasFile struct {
+	Nodes []*Node
+}
+
+func (f *AliasFile) String() string {
+	var b strings.Builder
+	for _, n := range f.Nodes {
+		b.WriteString(n.Raw)
+	}
+	return b.String()
+}
+
+// Aliases returns every alias and function defined at the top level of the
+// file, in file order.
+func (f *AliasFile) Aliases() []Alias {
+	aliases := []Alias{}
+	for _, n := range f.Nodes {
+		aliases = append(aliases, n.Aliases...)
+	}
+	return aliases
+}
+
+// ParseError reports a syntax error at a position of the alias file.
+type ParseError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// parseAliasFile parses the POSIX sh subset aliasman stores: alias commands,
+// function definitions and comments. Any other statement is tokenized just
+// enough to find where it ends and is kept verbatim as a NodeOther.
+func parseAliasFile(src string) (*AliasFile, error) {
+	p := &parser{lex: &lexer{src: src}}
+	f := &AliasFile{}
+
+	for p.lex.pos < len(src) {
+		start := p.lex.pos
+		n, err := p.parseNode()
+		if err != nil {
+			return nil, err
+		}
+		n.Raw = src[start:p.lex.pos]
+		n.Line, _ = p.lex.position(start)
+		f.Nodes = append(f.Nodes, n)
+	}
+
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokOp
+	tokNewline
+)
+
+type token struct {
+	kind  tokenKind
+	text  string // source text of a word, or the operator itself
+	value string // word with quoting removed
+	start int
+	end   int
+}
+
+type heredoc struct {
+	delim     string
+	stripTabs bool
+	start     int
+}
+
+type lexer struct {
+	src      string
+	pos      int
+	heredocs []heredoc // here-documents whose bodies start after the next newline
+}
+
+// position returns the 1-based line and column of a byte offset.
+func (l *lexer) position(offset int) (int, int) {
+	line := 1 + strings.Count(l.src[:offset], "\n")
+	lineStart := strings.LastIndexByte(l.src[:offset], '\n') + 1
+	return line, utf8.RuneCountInString(l.src[lineStart:offset]) + 1
+}
+
+func (l *lexer) errorf(offset int, format string, args ...any) error {
+	line, col := l.position(offset)
+	return &ParseError{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) peekByte(offset int) byte {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+func isWordBreak(c byte) bool {
+	return strings.IndexByte(" \t\n;&|()<>", c) >= 0
+}
+
+// operators is ordered so that longer operators match first.
+var operators = []string{
+	"&>>", "<<<", "<<-",
+	"&&", "||", ";;", "|&", "<<", ">>", "<&", ">&", "<>", ">|", "&>",
+	";", "&", "|", "(", ")", "<", ">",
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\r' {
+			l.pos++
+		} else if c == '\\' && l.peekByte(1) == '\n' {
+			l.pos += 2
+		} else if c == '#' {
+			if end := strings.IndexByte(l.src[l.pos:], '\n'); end >= 0 {
+				l.pos += end
+			} else {
+				l.pos = len(l.src)
+			}
+		} else {
+			break
+		}
+	}
+
+	start := l.pos
+	if l.pos >= len(l.src) {
+		if len(l.heredocs) > 0 {
+			return token{}, l.errorf(l.heredocs[0].start, "here-document delimited by %q is never terminated", l.heredocs[0].delim)
+		}
+		return token{kind: tokEOF, start: start, end: start}, nil
+	}
+
+	c := l.src[l.pos]
+	if c == '\n' {
+		l.pos++
+		if err := l.readHeredocs(); err != nil {
+			return token{}, err
+		}
+		return token{kind: tokNewline, text: "\n", start: start, end: l.pos}, nil
+	}
+	if isWordBreak(c) {
+		return l.readOp()
+	}
+	return l.readWord()
+}
+
+func (l *lexer) readOp() (token, error) {
+	start := l.pos
+	for _, op := range operators {
+		if !strings.HasPrefix(l.src[l.pos:], op) {
+			continue
+		}
+		l.pos += len(op)
+		if op == "<<" || op == "<<-" {
+			for l.peekByte(0) == ' ' || l.peekByte(0) == '\t' {
+				l.pos++
+			}
+			if l.pos >= len(l.src) || isWordBreak(l.src[l.pos]) {
+				return token{}, l.errorf(start, "missing here-document delimiter")
+			}
+			delim, err := l.readWord()
+			if err != nil {
+				return token{}, err
+			}
+			l.heredocs = append(l.heredocs, heredoc{delim: delim.value, stripTabs: op == "<<-", start: start})
+		}
+		return token{kind: tokOp, text: op, start: start, end: l.pos}, nil
+	}
+	return token{}, l.errorf(start, "unexpected character %q", l.src[start])
+}
+
+// readHeredocs consumes the bodies of pending here-documents. It is called
+// right after the newline that ends the line they were introduced on.
+func (l *lexer) readHeredocs() error {
+	for _, h := range l.heredocs {
+		for {
+			if l.pos >= len(l.src) {
+				return l.errorf(h.start, "here-document delimited by %q is never terminated", h.delim)
+			}
+			line := l.src[l.pos:]
+			if end := strings.IndexByte(line, '\n'); end >= 0 {
+				line = line[:end+1]
+			}
+			l.pos += len(line)
+
+			line = strings.TrimSuffix(line, "\n")
+			if h.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == h.delim {
+				break
+			}
+		}
+	}
+	l.heredocs = nil
+	return nil
+}
+
+func (l *lexer) readWord() (token, error) {
+	start := l.pos
+	var value strings.Builder
+
+	for l.pos < len(l.src) && !isWordBreak(l.src[l.pos]) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\'':
+			end := strings.IndexByte(l.src[l.pos+1:], '\'')
+			if end < 0 {
+				return token{}, l.errorf(l.pos, "unterminated single-quoted string")
+			}
+			value.WriteString(l.src[l.pos+1 : l.pos+1+end])
+			l.pos += end + 2
+		case c == '"':
+			s, err := l.readDoubleQuoted()
+			if err != nil {
+				return token{}, err
+			}
+			value.WriteString(s)
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				value.WriteByte(c)
+				l.pos++
+			} else if l.src[l.pos+1] == '\n' {
+				l.pos += 2
+			} else {
+				_, size := utf8.DecodeRuneInString(l.src[l.pos+1:])
+				value.WriteString(l.src[l.pos+1 : l.pos+1+size])
+				l.pos += 1 + size
+			}
+		case c == '$' && l.peekByte(1) == '\'':
+			s, err := l.readANSIC()
+			if err != nil {
+				return token{}, err
+			}
+			value.WriteString(s)
+		case c == '$' && l.peekByte(1) == '(':
+			s, err := l.skipCommandSubstitution()
+			if err != nil {
+				return token{}, err
+			}
+			value.WriteString(s)
+		case c == '$' && l.peekByte(1) == '{':
+			s, err := l.skipBraced()
+			if err != nil {
+				return token{}, err
+			}
+			value.WriteString(s)
+		case c == '`':
+			s, err := l.skipBackquoted()
+			if err != nil {
+				return token{}, err
+			}
+			value.WriteString(s)
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{kind: tokWord, text: l.src[start:l.pos], value: value.String(), start: start, end: l.pos}, nil
+}
+
+// readDoubleQuoted consumes a "..." string and returns its value. Command and
+// parameter substitutions are kept as written.
+func (l *lexer) readDoubleQuoted() (string, error) {
+	start := l.pos
+	l.pos++
+	var value strings.Builder
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return value.String(), nil
+		case c == '\\' && l.pos+1 < len(l.src):
+			next := l.src[l.pos+1]
+			if next == '\n' {
+				// line continuation
+			} else if strings.IndexByte("$`\"\\", next) >= 0 {
+				value.WriteByte(next)
+			} else {
+				value.WriteByte(c)
+				value.WriteByte(next)
+			}
+			l.pos += 2
+		case c == '$' && l.peekByte(1) == '(':
+			s, err := l.skipCommandSubstitution()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(s)
+		case c == '$' && l.peekByte(1) == '{':
+			s, err := l.skipBraced()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(s)
+		case c == '`':
+			s, err := l.skipBackquoted()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(s)
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return "", l.errorf(start, "unterminated double-quoted string")
+}
+
+var ansiCEscapes = map[byte]string{
+	'a': "\a", 'b': "\b", 'e': "\x1b", 'E': "\x1b", 'f': "\f", 'n': "\n",
+	'r': "\r", 't': "\t", 'v': "\v", '\\': "\\", '\'': "'", '"': "\"", '?': "?",
+}
+
+// readANSIC consumes a $'...' string, decoding the common escapes.
+func (l *lexer) readANSIC() (string, error) {
+	start := l.pos
+	l.pos += 2
+	var value strings.Builder
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\'':
+			l.pos++
+			return value.String(), nil
+		case c == '\\' && l.pos+1 < len(l.src):
+			if s, ok := ansiCEscapes[l.src[l.pos+1]]; ok {
+				value.WriteString(s)
+			} else {
+				value.WriteString(l.src[l.pos : l.pos+2])
+			}
+			l.pos += 2
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return "", l.errorf(start, "unterminated $'...' string")
+}
+
+// skipBraced consumes a ${ ... } expansion, honoring quotes and nesting, and
+// returns its source text.
+func (l *lexer) skipBraced() (string, error) {
+	start := l.pos
+	l.pos += 2
+	depth := 1
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '\'':
+			end := strings.IndexByte(l.src[l.pos+1:], '\'')
+			if end < 0 {
+				return "", l.errorf(l.pos, "unterminated single-quoted string")
+			}
+			l.pos += end + 2
+		case '"':
+			if _, err := l.readDoubleQuoted(); err != nil {
+				return "", err
+			}
+		case '`':
+			if _, err := l.skipBackquoted(); err != nil {
+				return "", err
+			}
+		case '\\':
+			l.pos += 2
+		default:
+			if c == '{' {
+				depth++
+			} else if c == '}' {
+				depth--
+			}
+			l.pos++
+			if depth == 0 {
+				return l.src[start:l.pos], nil
+			}
+		}
+	}
+
+	return "", l.errorf(start, "unterminated \"${\"")
+}
+
+// skipCommandSubstitution consumes a $( ... ) command substitution and
+// returns its source text. The commands inside are tokenized like those at
+// the top level, so that the ")" ending a case pattern is not taken for the
+// end of the substitution.
+func (l *lexer) skipCommandSubstitution() (string, error) {
+	start := l.pos
+	l.pos += 2
+	var stack []string // open compound commands and subshells
+	cmdStart := true
+
+	for {
+		t, err := l.next()
+		if err != nil {
+			return "", err
+		}
+
+		switch t.kind {
+		case tokEOF:
+			return "", l.errorf(start, "unterminated \"$(\"")
+		case tokNewline:
+			cmdStart = true
+		case tokOp:
+			switch {
+			case t.text == "(":
+				stack = append(stack, t.text)
+			case t.text == ")" && len(stack) == 0:
+				return l.src[start:l.pos], nil
+			case t.text == ")" && stack[len(stack)-1] == "(":
+				stack = stack[:len(stack)-1]
+			}
+			// Any other ")" ends a case pattern.
+			cmdStart = separators[t.text]
+		case tokWord:
+			if cmdStart && t.text == t.value {
+				if _, ok := compoundEnd[t.text]; ok {
+					stack = append(stack, t.text)
+					cmdStart = commandWords[t.text]
+					continue
+				}
+				if len(stack) > 0 && compoundEnd[stack[len(stack)-1]] == t.text {
+					stack = stack[:len(stack)-1]
+					cmdStart = false
+					continue
+				}
+			}
+			cmdStart = cmdStart && commandWords[t.text]
+		}
+	}
+}
+
+func (l *lexer) skipBackquoted() (string, error) {
+	start := l.pos
+	l.pos++
+
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '`':
+			l.pos++
+			return l.src[start:l.pos], nil
+		default:
+			l.pos++
+		}
+	}
+
+	return "", l.errorf(start, "unterminated backquoted command")
+}
+
+type parser struct {
+	lex *lexer
+}
+
+// parseNode parses the statement starting at the current position. Blank and
+// comment lines become their own nodes; everything else runs until the first
+// newline outside of any compound command.
+func (p *parser) parseNode() (*Node, error) {
+	src := p.lex.src
+	rest := src[p.lex.pos:]
+	line := rest
+	if end := strings.IndexByte(rest, '\n'); end >= 0 {
+		line = rest[:end+1]
+	}
+
+	switch trimmed := strings.TrimLeft(line, " \t\r"); {
+	case trimmed == "" || trimmed == "\n":
+		p.lex.pos += len(line)
+		return &Node{Kind: NodeBlank}, nil
+	case strings.HasPrefix(trimmed, "#"):
+		p.lex.pos += len(line)
+		return &Node{Kind: NodeComment}, nil
+	}
+
+	return p.parseStatement()
+}
+
+// compoundEnd maps each reserved word that opens a compound command to the
+// reserved word closing it.
+var compoundEnd = map[string]string{
+	"{": "}", "if": "fi", "case": "esac",
+	"while": "done", "until": "done", "for": "done", "select": "done",
+}
+
+// commandWords are reserved words after which another command starts.
+var commandWords = map[string]bool{
+	"{": true, "then": true, "do": true, "else": true, "elif": true,
+	"if": true, "while": true, "until": true, "!": true, "time": true,
+}
+
+var separators = map[string]bool{
+	";": true, ";;": true, "&": true, "&&": true, "|": true, "||": true,
+	"|&": true, "(": true, ")": true,
+}
+
+func (p *parser) parseStatement() (*Node, error) {
+	n := &Node{}
+	var (
+		stack     []token // open compound commands and subshells
+		words     []token // words of the current top-level simple command
+		cmdStart  = true
+		redirect  bool
+		fnName    string
+		fnBody    int
+		fnStart   int
+		aliasCmds int
+		functions int
+		otherCmds int
+	)
+
+	// endCommand classifies the simple command ended by sep ("" at the end of
+	// the line).
+	endCommand := func(sep string) {
+		if len(words) == 0 {
+			return
+		}
+		switch {
+		case words[0].text == "alias":
+			aliasCmds++
+			n.Aliases = append(n.Aliases, p.aliasDefinitions(words)...)
+		case sep == "&&" && isZshGuard(words):
+			// The guard in front of zsh-only aliases is part of the definition.
+		default:
+			otherCmds++
+		}
+		words = nil
+	}
+
+	for {
+		t, err := p.lex.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t.kind {
+		case tokEOF, tokNewline:
+			if len(stack) > 0 && t.kind == tokNewline {
+				cmdStart, redirect = true, false
+				continue
+			}
+			if len(stack) > 0 {
+				open := stack[len(stack)-1]
+				return nil, p.lex.errorf(open.start, "%q is never closed", open.text)
+			}
+			endCommand("")
+			switch {
+			case functions == 1 && aliasCmds == 0 && otherCmds == 0:
+				n.Kind = NodeFunction
+			case functions == 0 && aliasCmds > 0 && otherCmds == 0:
+				n.Kind = NodeAlias
+			default:
+				n.Kind = NodeOther
+			}
+			return n, nil
+
+		case tokOp:
+			switch t.text {
+			case "(":
+				if len(stack) == 0 {
+					otherCmds++
+				}
+				stack = append(stack, t)
+			case ")":
+				// An unmatched ")" ends a case pattern.
+				if len(stack) > 0 && stack[len(stack)-1].text == "(" {
+					stack = stack[:len(stack)-1]
+					if len(stack) == 0 && fnName != "" {
+						line, _ := p.lex.position(fnStart)
+						n.Aliases = append(n.Aliases, Alias{
+							Name:    fnName,
+							Command: strings.TrimSpace(p.lex.src[fnBody:t.end]),
+							Type:    "function",
+							Line:    line,
+						})
+						fnName = ""
+					}
+				}
+			}
+			if len(stack) == 0 && separators[t.text] {
+				endCommand(t.text)
+			}
+			cmdStart = separators[t.text]
+			redirect = !cmdStart && t.text != "<<" && t.text != "<<-"
+
+		case tokWord:
+			if redirect {
+				redirect = false
+				continue
+			}
+
+			if cmdStart && len(stack) == 0 {
+				name, brace, ok, err := p.parseFunctionHeader(t)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					functions++
+					fnName, fnBody, fnStart = name, brace.end, t.start
+					if brace.text == "(" {
+						// A subshell body is kept whole, parentheses included.
+						fnBody = brace.start
+					}
+					stack = append(stack, brace)
+					continue
+				}
+			}
+
+			if cmdStart && t.text == t.value {
+				if _, ok := compoundEnd[t.text]; ok {
+					if len(stack) == 0 {
+						otherCmds++
+					}
+					stack = append(stack, t)
+					cmdStart = commandWords[t.text]
+					continue
+				}
+				if t.text == "}" || t.text == "fi" || t.text == "esac" || t.text == "done" {
+					if len(stack) == 0 || compoundEnd[stack[len(stack)-1].text] != t.text {
+						return nil, p.lex.errorf(t.start, "unexpected %q", t.text)
+					}
+					stack = stack[:len(stack)-1]
+					if len(stack) == 0 && fnName != "" {
+						line, _ := p.lex.position(fnStart)
+						n.Aliases = append(n.Aliases, Alias{
+							Name:    fnName,
+							Command: functionBody(p.lex.src[fnBody:t.start]),
+							Type:    "function",
+							Line:    line,
+						})
+						fnName = ""
+					}
+					cmdStart = false
+					continue
+				}
+			}
+
+			if len(stack) == 0 {
+				words = append(words, t)
+			}
+			cmdStart = cmdStart && commandWords[t.text]
+		}
+	}
+}
+
+// parseFunctionHeader recognizes "function NAME [()] {" and "NAME() {" at the
+// start of a command, where the body can also be a "( ... )" subshell. On
+// success it returns the name and the token opening the body. A word that is
+// not a valid function name, such as "arr=" in "arr=(a b)", is no header.
+func (p *parser) parseFunctionHeader(first token) (string, token, bool, error) {
+	name := first
+	if first.text != "function" && (first.text != first.value || !functionNamePattern.MatchString(first.value)) {
+		return "", token{}, false, nil
+	}
+	if first.text == "function" {
+		t, err := p.lex.next()
+		if err != nil {
+			return "", token{}, false, err
+		}
+		if t.kind != tokWord {
+			return "", token{}, false, p.lex.errorf(t.start, "expected function name")
+		}
+		name = t
+	}
+
+	saved := *p.lex
+	if t, err := p.lex.next(); err == nil && t.kind == tokOp && t.text == "(" {
+		if err := p.expectOp(")"); err != nil {
+			return "", token{}, false, err
+		}
+	} else if first.text != "function" {
+		*p.lex = saved
+		return "", token{}, false, nil
+	} else {
+		*p.lex = saved
+	}
+
+	for {
+		t, err := p.lex.next()
+		if err != nil {
+			return "", token{}, false, err
+		}
+		if t.kind == tokNewline {
+			continue
+		}
+		if (t.kind != tokWord || t.text != "{") && (t.kind != tokOp || t.text != "(") {
+			return "", token{}, false, p.lex.errorf(t.start, "expected '{' or '(' to start the body of function %q", name.value)
+		}
+		return name.value, t, true, nil
+	}
+}
+
+func (p *parser) expectOp(op string) error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != tokOp || t.text != op {
+		return p.lex.errorf(t.start, "expected %q", op)
+	}
+	return nil
+}
+
+// aliasDefinitions returns the NAME=VALUE operands of an alias command. The
+// zsh options -g and -s make them global and suffix aliases.
+func (p *parser) aliasDefinitions(words []token) []Alias {
+	aliases := []Alias{}
+	aliasType := "alias"
+	options := true
+	for _, w := range words[1:] {
+		if options && strings.HasPrefix(w.value, "-") {
+			switch w.value {
+			case "--":
+				options = false
+			case "-g":
+				aliasType = "global"
+			case "-s":
+				aliasType = "suffix"
+			}
+			continue
+		}
+		options = false
+		name, command, ok := strings.Cut(w.value, "=")
+		if !ok || name == "" {
+			continue
+		}
+		line, _ := p.lex.position(w.start)
+		aliases = append(aliases, Alias{Name: name, Command: command, Type: aliasType, Line: line})
+	}
+	return aliases
+}
+
+// zshGuard is written in front of zsh-only definitions so that bash, which
+// reads the same alias file, skips them.
+const zshGuard = `[ -n "$ZSH_VERSION" ] && `
+
+// isZshGuard reports whether words are the test of zshGuard.
+func isZshGuard(words []token) bool {
+	want := []string{"[", "-n", "$ZSH_VERSION", "]"}
+	if len(words) != len(want) {
+		return false
+	}
+	for i, w := range words {
+		if w.value != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// functionBody strips the line break after "{" and before "}" from the source
+// of a function body, leaving the lines in between as written.
+func functionBody(body string) string {
+	if !strings.Contains(body, "\n") {
+		return strings.TrimSpace(body)
+	}
+	body = strings.TrimPrefix(strings.TrimLeft(body, " \t"), "\n")
+	return strings.TrimSuffix(strings.TrimRight(body, " \t"), "\n")
+}
+
+// parseDefinition parses text that must hold exactly one alias or function,
+// such as a definition edited by hand or generated by the LLM.
+func parseDefinition(text string) (Alias, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	file, err := parseAliasFile(text)
+	if err != nil {
+		return Alias{}, err
+	}
+
+	aliases := file.Aliases()
+	if len(aliases) != 1 {
+		return Alias{}, fmt.Errorf("expected exactly one alias or function, found %d", len(aliases))
+	}
+	for _, n := range file.Nodes {
+		if n.Kind == NodeOther {
+			return Alias{}, &ParseError{Line: n.Line, Column: 1, Msg: "only a single alias or function definition is allowed"}
+		}
+	}
+	return aliases[0], nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseAliasFileRoundTrip(t *testing.T) {
+	tests := []string{
+		"",
+		"\n\n",
+		"# comment\n  # indented comment\n",
+		"alias ll='ls -l'\n",
+		"alias ll='ls -l' la=\"ls -a\"\nalias g=git",
+		"alias -g G='| grep'\n",
+		"[ -n \"$ZSH_VERSION\" ] && alias -s txt=less\n",
+		"greet() {\n  echo \"hello $1\"\n}\n",
+		"function up { cd ..; }\n",
+		"sub() (cd /tmp && ls)\n",
+		"arr=(a b c)\n",
+		"export PATH=\"$HOME/bin:$PATH\"\r\n",
+		"if true; then\n  alias x=y\nfi\n",
+		"cat <<EOF\nalias not=parsed\nEOF\n",
+		"alias q=$'it\\'s'\n",
+		"f() { x=$(case a in a) echo;; esac); }\n",
+		"alias d=\"$(date +%F)\" n=$((1 + (2 * 3)))\n",
+	}
+	for _, src := range tests {
+		file, err := parseAliasFile(src)
+		if err != nil {
+			t.Errorf("parseAliasFile(%q): %v", src, err)
+			continue
+		}
+		if got := file.String(); got != src {
+			t.Errorf("parseAliasFile(%q).String() = %q", src, got)
+		}
+	}
+}
+
+func TestParseAliasFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		kinds   []NodeKind
+		aliases []Alias
+	}{
+		{
+			name:    "alias",
+			src:     "alias ll='ls -l'\n",
+			kinds:   []NodeKind{NodeAlias},
+			aliases: []Alias{{Name: "ll", Command: "ls -l", Type: "alias", Line: 1}},
+		},
+		{
+			name:  "several aliases on one line",
+			src:   "alias a=b c='d e'\n",
+			kinds: []NodeKind{NodeAlias},
+			aliases: []Alias{
+				{Name: "a", Command: "b", Type: "alias", Line: 1},
+				{Name: "c", Command: "d e", Type: "alias", Line: 1},
+			},
+		},
+		{
+			name:    "zsh global alias behind the guard",
+			src:     "[ -n \"$ZSH_VERSION\" ] && alias -g G='| grep'\n",
+			kinds:   []NodeKind{NodeAlias},
+			aliases: []Alias{{Name: "G", Command: "| grep", Type: "global", Line: 1}},
+		},
+		{
+			name:    "function",
+			src:     "# greet\ngreet() {\n  echo hi\n}\n",
+			kinds:   []NodeKind{NodeComment, NodeFunction},
+			aliases: []Alias{{Name: "greet", Command: "  echo hi", Type: "function", Line: 2}},
+		},
+		{
+			name:    "function keyword",
+			src:     "function up { cd ..; }\n",
+			kinds:   []NodeKind{NodeFunction},
+			aliases: []Alias{{Name: "up", Command: "cd ..;", Type: "function", Line: 1}},
+		},
+		{
+			name:    "subshell body",
+			src:     "sub() (cd /tmp && ls)\n",
+			kinds:   []NodeKind{NodeFunction},
+			aliases: []Alias{{Name: "sub", Command: "(cd /tmp && ls)", Type: "function", Line: 1}},
+		},
+		{
+			name:    "case inside a command substitution",
+			src:     "f() { x=$(case a in (a) echo;; b) echo \")\";; esac); }\n",
+			kinds:   []NodeKind{NodeFunction},
+			aliases: []Alias{{Name: "f", Command: "x=$(case a in (a) echo;; b) echo \")\";; esac);", Type: "function", Line: 1}},
+		},
+		{
+			name:    "array assignment",
+			src:     "arr=(a b c)\n",
+			kinds:   []NodeKind{NodeOther},
+			aliases: []Alias{},
+		},
+		{
+			name:    "alias mixed with another command",
+			src:     "[[ -n $X ]] && alias a=b\n",
+			kinds:   []NodeKind{NodeOther},
+			aliases: []Alias{{Name: "a", Command: "b", Type: "alias", Line: 1}},
+		},
+		{
+			name:    "here-document",
+			src:     "cat <<EOF\nalias not=parsed\nEOF\n",
+			kinds:   []NodeKind{NodeOther},
+			aliases: []Alias{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parseAliasFile(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kinds []NodeKind
+			for _, n := range file.Nodes {
+				kinds = append(kinds, n.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("kinds = %v, want %v", kinds, tt.kinds)
+			}
+			if got := file.Aliases(); !reflect.DeepEqual(got, tt.aliases) {
+				t.Errorf("aliases = %+v, want %+v", got, tt.aliases)
+			}
+		})
+	}
+}
+
+func TestParseAliasFileErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+	}{
+		{"alias a='unterminated\n", 1},
+		{"\nf() {\n  echo\n", 2},
+		{"fi\n", 1},
+		{"function 'f' x\n", 1},
+		{"alias a=$(case x in x) echo\n", 1},
+		{"alias a=${x\n", 1},
+	}
+	for _, tt := range tests {
+		_, err := parseAliasFile(tt.src)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("parseAliasFile(%q) = %v, want a *ParseError", tt.src, err)
+			continue
+		}
+		if parseErr.Line != tt.line {
+			t.Errorf("parseAliasFile(%q) failed on line %d, want %d", tt.src, parseErr.Line, tt.line)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// storeFileName is the name of the store inside the aliasman config directory.
+const storeFileName = "aliases.json"
+
+// appPaths are the files and directories aliasman works with.
+type appPaths struct {
+	Store     string // the JSON store, the source of truth
+	AliasFile string // where installing generates the alias file, unless the store names one
+	StateDir  string // state that can be rebuilt or lost, such as backups
+	// LegacyAliasFile is the alias file of older versions, imported into the
+	// store on first start. It is empty for relocated instances, which must
+	// not take over the user's aliases.
+	LegacyAliasFile string
+}
+
+// relocated reports whether the instance was moved with --config or
+// $ALIASMAN_HOME. Relocated instances are isolated: they only touch the shell
+// config files recorded in their store or named explicitly, never the ones
+// found in the home directory.
+func (p appPaths) relocated() bool {
+	return p.LegacyAliasFile == ""
+}
+
+// resolvePaths works out where aliasman keeps its files. configFlag, the
+// value of --config, names the store, and the other files go next to it.
+// Otherwise $ALIASMAN_HOME puts everything in one directory, and failing that
+// the XDG base directories are used.
+func resolvePaths(homeDir, configFlag string) (appPaths, error) {
+	if configFlag != "" {
+		store, err := filepath.Abs(configFlag)
+		if err != nil {
+			return appPaths{}, fmt.Errorf("--config: %w", err)
+		}
+		base := strings.TrimSuffix(store, filepath.Ext(store))
+		paths := appPaths{
+			Store:     store,
+			AliasFile: base + ".sh",
+			StateDir:  base + ".state",
+		}
+		// The files next to the store are named after it, so the store
+		// cannot have their extensions.
+		if paths.AliasFile == store || paths.StateDir == store {
+			return appPaths{}, fmt.Errorf("--config: %s would be overwritten by the files generated next to it; use a name ending in .json", configFlag)
+		}
+		return paths, nil
+	}
+
+	if home := os.Getenv("ALIASMAN_HOME"); home != "" {
+		home, err := filepath.Abs(home)
+		if err != nil {
+			return appPaths{}, fmt.Errorf("ALIASMAN_HOME: %w", err)
+		}
+		return appPaths{
+			Store:     filepath.Join(home, storeFileName),
+			AliasFile: filepath.Join(home, "aliases.sh"),
+			StateDir:  filepath.Join(home, "state"),
+		}, nil
+	}
+
+	return appPaths{
+		Store:           filepath.Join(configHome(homeDir), "aliasman", storeFileName),
+		AliasFile:       filepath.Join(xdgDir("XDG_DATA_HOME", homeDir, ".local/share"), "aliasman", "aliases.sh"),
+		StateDir:        filepath.Join(xdgDir("XDG_STATE_HOME", homeDir, ".local/state"), "aliasman"),
+		LegacyAliasFile: filepath.Join(homeDir, aliasFileName),
+	}, nil
+}
+
+// xdgDir returns the directory named by the XDG environment variable name, or
+// fallback inside homeDir when it is unset. The spec says to ignore relative
+// paths.
+func xdgDir(name, homeDir, fallback string) string {
+	if dir := os.Getenv(name); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(homeDir, fallback)
+}
+
+// configHome returns $XDG_CONFIG_HOME, or ~/.config when it is not set.
+func configHome(homeDir string) string {
+	return xdgDir("XDG_CONFIG_HOME", homeDir, ".config")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolvePaths(t *testing.T) {
+	const home = "/home/u"
+	tests := []struct {
+		name   string
+		config string
+		env    map[string]string
+		want   appPaths
+		err    bool
+	}{
+		{
+			name: "defaults",
+			want: appPaths{
+				Store:           "/home/u/.config/aliasman/aliases.json",
+				AliasFile:       "/home/u/.local/share/aliasman/aliases.sh",
+				StateDir:        "/home/u/.local/state/aliasman",
+				LegacyAliasFile: "/home/u/.aliasman_aliases",
+			},
+		},
+		{
+			name: "XDG directories",
+			env:  map[string]string{"XDG_CONFIG_HOME": "/cfg", "XDG_DATA_HOME": "/data", "XDG_STATE_HOME": "/state"},
+			want: appPaths{
+				Store:           "/cfg/aliasman/aliases.json",
+				AliasFile:       "/data/aliasman/aliases.sh",
+				StateDir:        "/state/aliasman",
+				LegacyAliasFile: "/home/u/.aliasman_aliases",
+			},
+		},
+		{
+			name: "relative XDG directories are ignored",
+			env:  map[string]string{"XDG_CONFIG_HOME": "cfg"},
+			want: appPaths{
+				Store:           "/home/u/.config/aliasman/aliases.json",
+				AliasFile:       "/home/u/.local/share/aliasman/aliases.sh",
+				StateDir:        "/home/u/.local/state/aliasman",
+				LegacyAliasFile: "/home/u/.aliasman_aliases",
+			},
+		},
+		{
+			name: "ALIASMAN_HOME",
+			env:  map[string]string{"ALIASMAN_HOME": "/am", "XDG_CONFIG_HOME": "/cfg"},
+			want: appPaths{
+				Store:     "/am/aliases.json",
+				AliasFile: "/am/aliases.sh",
+				StateDir:  "/am/state",
+			},
+		},
+		{
+			name:   "--config",
+			config: "/dotfiles/work.json",
+			env:    map[string]string{"ALIASMAN_HOME": "/am"},
+			want: appPaths{
+				Store:     "/dotfiles/work.json",
+				AliasFile: "/dotfiles/work.sh",
+				StateDir:  "/dotfiles/work.state",
+			},
+		},
+		{
+			name:   "--config without an extension",
+			config: "/dotfiles/work",
+			want: appPaths{
+				Store:     "/dotfiles/work",
+				AliasFile: "/dotfiles/work.sh",
+				StateDir:  "/dotfiles/work.state",
+			},
+		},
+		{name: "--config naming the alias file", config: "/dotfiles/work.sh", err: true},
+		{name: "--config naming the state directory", config: "/dotfiles/work.state", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"ALIASMAN_HOME", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME"} {
+				t.Setenv(name, tt.env[name])
+			}
+			got, err := resolvePaths(home, tt.config)
+			if tt.err {
+				if err == nil {
+					t.Errorf("resolvePaths = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolvePaths = %+v, want %+v", got, tt.want)
+			}
+			if got.relocated() != (tt.want.LegacyAliasFile == "") {
+				t.Errorf("relocated() = %v", got.relocated())
+			}
+		})
+	}
+}
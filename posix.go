@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// posixSyntaxRules catch the bash extensions that a strictly POSIX shell such
+// as dash rejects.
+var posixSyntaxRules = []syntaxRule{
+	{regexp.MustCompile(`\[\[`), "[[ ... ]] tests"},
+	{regexp.MustCompile(`(^|[^$])\(\(`), "(( ... )) arithmetic"},
+	{regexp.MustCompile(`[<>]\(`), "process substitution"},
+	{regexp.MustCompile(`<<<`), "here-strings"},
+	{regexp.MustCompile(`&>|\|&`), "&> and |& redirections"},
+	{regexp.MustCompile(`\$'`), "$'...' quoting"},
+	{regexp.MustCompile(`\$\{!|\$\{[A-Za-z_][A-Za-z0-9_]*(/|:[0-9 ]|\^|,)`), "bash parameter expansions"},
+	{regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*\[|[A-Za-z_][A-Za-z0-9_]*\+?=\(`), "arrays"},
+	{regexp.MustCompile(`(^|[^$])\{[^{}\s$]*(,|\.\.)[^{}\s]*\}`), "brace expansion"},
+	{regexp.MustCompile(`\$\{?(RANDOM|SECONDS|PIPESTATUS|FUNCNAME|BASH_[A-Z]+)\b`), "bash variables"},
+	{regexp.MustCompile(`(?m)(^|[;&|(]\s*)source\s`), "source (use .)"},
+	{regexp.MustCompile(`(?m)(^|[;&|(]\s*)(local|declare|typeset)\s`), "local variables"},
+	{regexp.MustCompile(`(?m)(^|[;&|(]\s*)(shopt|select|function)\s`), "bash builtins and keywords"},
+}
+
+// posixNamePattern matches the function names POSIX allows.
+var posixNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// renderPosix renders definitions for a strictly POSIX sh. Aliases are not
+// expanded in scripts and behave differently across shells, so they are
+// written as functions that pass their arguments on, and functions lose the
+// bash-only "function" keyword.
+func renderPosix(alias Alias, _ Config) (string, error) {
+	if _, ok := zshAliasFlags[alias.Type]; ok {
+		return "", fmt.Errorf("%s aliases are only supported by zsh", alias.Type)
+	}
+	if problems := unsupportedSyntax(alias.Command, posixSyntaxRules); len(problems) > 0 {
+		return "", fmt.Errorf("uses bash syntax POSIX sh does not support: %s", strings.Join(problems, ", "))
+	}
+	if !posixNamePattern.MatchString(alias.Name) {
+		return "", fmt.Errorf("%q is not a valid POSIX function name", alias.Name)
+	}
+
+	body := alias.Command
+	if alias.Type == "alias" {
+		// An alias that wraps the command of the same name, such as
+		// ls='ls --color', would call itself as a function.
+		if first, _, _ := strings.Cut(body, " "); first == alias.Name {
+			body = "command " + body
+		}
+		body = "    " + body + ` "$@"`
+	}
+	text := fmt.Sprintf("%s() {\n%s\n}\n", alias.Name, body)
+
+	file, err := parseAliasFile(text)
+	if err != nil || len(file.Nodes) != 1 || file.Nodes[0].Kind != NodeFunction {
+		return "", fmt.Errorf("cannot be written as a POSIX function")
+	}
+	return text, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPosix(t *testing.T) {
+	tests := []struct {
+		name  string
+		alias Alias
+		want  string
+		err   string // a part of the error, if rendering fails
+	}{
+		{
+			name:  "alias",
+			alias: Alias{Name: "ll", Type: "alias", Command: "ls -l"},
+			want:  "ll() {\n    ls -l \"$@\"\n}\n",
+		},
+		{
+			name:  "alias wrapping its own name",
+			alias: Alias{Name: "ls", Type: "alias", Command: "ls --color"},
+			want:  "ls() {\n    command ls --color \"$@\"\n}\n",
+		},
+		{
+			name:  "function",
+			alias: Alias{Name: "greet", Type: "function", Command: "  echo \"hi $1\""},
+			want:  "greet() {\n  echo \"hi $1\"\n}\n",
+		},
+		{
+			name:  "global alias",
+			alias: Alias{Name: "G", Type: "global", Command: "| grep"},
+			err:   "only supported by zsh",
+		},
+		{
+			name:  "invalid name",
+			alias: Alias{Name: "g.st", Type: "alias", Command: "git status"},
+			err:   "not a valid POSIX function name",
+		},
+		{
+			name:  "bash syntax",
+			alias: Alias{Name: "f", Type: "function", Command: "local x=$'a'; source ~/.env; echo {a,b}"},
+			err:   "$'...' quoting, brace expansion, source (use .), local variables",
+		},
+		{
+			name:  "arithmetic expansion is POSIX",
+			alias: Alias{Name: "add", Type: "function", Command: "echo $(( $1 + $2 ))"},
+			want:  "add() {\necho $(( $1 + $2 ))\n}\n",
+		},
+		{
+			name:  "arrays",
+			alias: Alias{Name: "f", Type: "function", Command: "a=(1 2); echo ${a[0]}"},
+			err:   "arrays",
+		},
+	}
+	for _, tt := range tests {
+		got, err := renderPosix(tt.alias, Config{})
+		switch {
+		case tt.err != "":
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: renderPosix = %q, %v; want an error containing %q", tt.name, got, err, tt.err)
+			}
+		case err != nil:
+			t.Errorf("%s: renderPosix: %v", tt.name, err)
+		case got != tt.want:
+			t.Errorf("%s: renderPosix = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
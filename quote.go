@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Bash rejects "/", "$", "`", "=", quotes and shell metacharacters in
+	// alias names. We are stricter and also leave out glob characters, since
+	// names are written unquoted.
+	aliasNamePattern    = regexp.MustCompile(`^[A-Za-z0-9_.:@%+,^][A-Za-z0-9_.:@%+,^-]*$`)
+	functionNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.:@+-]*$`)
+)
+
+var reservedWords = map[string]bool{
+	"!": true, "case": true, "do": true, "done": true, "elif": true, "else": true,
+	"esac": true, "fi": true, "for": true, "function": true, "if": true, "in": true,
+	"select": true, "then": true, "time": true, "until": true, "while": true,
+	"{": true, "}": true, "[[": true, "]]": true,
+}
+
+// shellQuote returns s quoted so that the shell reads it back unchanged. It
+// picks single quotes when possible, double quotes when s contains single
+// quotes but nothing double quotes would expand, and otherwise single quotes
+// with each single quote written as a closing quote, \' and an opening quote:
+//
+//	'it'\''s $HOME'
+func shellQuote(s string) string {
+	switch {
+	case !strings.Contains(s, "'"):
+		return "'" + s + "'"
+	case !strings.ContainsAny(s, "\"$`\\!"):
+		return `"` + s + `"`
+	default:
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+}
+
+// validateAlias checks that alias can be written to the alias file and read
+// back as the same definition.
+func validateAlias(alias Alias) error {
+	switch alias.Type {
+	case "alias", "global", "suffix":
+		if !aliasNamePattern.MatchString(alias.Name) {
+			return fmt.Errorf("%q is not a valid alias name", alias.Name)
+		}
+	case "function":
+		if !functionNamePattern.MatchString(alias.Name) || reservedWords[alias.Name] {
+			return fmt.Errorf("%q is not a valid function name", alias.Name)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", alias.Type)
+	}
+
+	if strings.TrimSpace(alias.Command) == "" {
+		return fmt.Errorf("the command of %q is empty", alias.Name)
+	}
+
+	file, err := parseAliasFile(formatAlias(alias))
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", alias.Type, alias.Name, err)
+	}
+	parsed := file.Aliases()
+	if len(file.Nodes) != 1 || len(parsed) != 1 ||
+		parsed[0].Name != alias.Name || parsed[0].Command != alias.Command || parsed[0].Type != alias.Type {
+		return fmt.Errorf("the %s body of %q does not read back as a single definition", alias.Type, alias.Name)
+	}
+	return validateMetadata(alias)
+}
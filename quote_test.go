@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "''"},
+		{"ls -l", "'ls -l'"},
+		{"echo $HOME", "'echo $HOME'"},
+		{"it's", `"it's"`},
+		{"it's $HOME", `'it'\''s $HOME'`},
+		{`say "it's"`, `'say "it'\''s"'`},
+		{"it's!", `'it'\''s!'`},
+	}
+	for _, tt := range tests {
+		got := shellQuote(tt.in)
+		if got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+
+		// Whatever the quoting, the alias file must read it back unchanged.
+		file, err := parseAliasFile("alias x=" + got + "\n")
+		if err != nil {
+			t.Errorf("parsing shellQuote(%q): %v", tt.in, err)
+			continue
+		}
+		if aliases := file.Aliases(); len(aliases) != 1 || aliases[0].Command != tt.in {
+			t.Errorf("shellQuote(%q) reads back as %+v", tt.in, aliases)
+		}
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		alias Alias
+		err   string // a part of the error, or "" if the alias is valid
+	}{
+		{Alias{Name: "ll", Type: "alias", Command: "ls -l"}, ""},
+		{Alias{Name: "g.st", Type: "alias", Command: "git status"}, ""},
+		{Alias{Name: "G", Type: "global", Command: "| grep"}, ""},
+		{Alias{Name: "txt", Type: "suffix", Command: "less"}, ""},
+		{Alias{Name: "greet", Type: "function", Command: "echo \"hello $1\""}, ""},
+		{Alias{Name: "multi", Type: "function", Command: "cd \"$1\"\nls"}, ""},
+		{Alias{Name: "a/b", Type: "alias", Command: "x"}, "not a valid alias name"},
+		{Alias{Name: "*", Type: "alias", Command: "x"}, "not a valid alias name"},
+		{Alias{Name: "-x", Type: "alias", Command: "x"}, "not a valid alias name"},
+		{Alias{Name: "1f", Type: "function", Command: "x"}, "not a valid function name"},
+		{Alias{Name: "done", Type: "function", Command: "x"}, "not a valid function name"},
+		{Alias{Name: "x", Type: "macro", Command: "x"}, "unknown type"},
+		{Alias{Name: "x", Type: "alias", Command: "  "}, "is empty"},
+		{Alias{Name: "f", Type: "function", Command: "}; rm -rf /; f() {"}, "does not read back"},
+	}
+	for _, tt := range tests {
+		err := validateAlias(tt.alias)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("validateAlias(%+v) = %v, want nil", tt.alias, err)
+		case tt.err != "" && err == nil:
+			t.Errorf("validateAlias(%+v) = nil, want an error", tt.alias)
+		case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+			t.Errorf("validateAlias(%+v) = %v, want an error containing %q", tt.alias, err, tt.err)
+		}
+	}
+}
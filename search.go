@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/rivo/tview"
+)
+
+// fuzzyMatch matches query against text, ignoring case: every rune of the
+// query must appear in text in order. It returns a score, higher for better
+// matches, and the rune positions in text that matched.
+func fuzzyMatch(query, text string) (int, []int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+	if len(q) == 0 {
+		return 0, nil, true
+	}
+
+	// A plain substring beats any scattered match.
+	if i := strings.Index(string(t), string(q)); i >= 0 {
+		start := len([]rune(string(t)[:i]))
+		positions := make([]int, len(q))
+		for j := range q {
+			positions[j] = start + j
+		}
+		score := 100 + 10*len(q)
+		if start == 0 || !isWordRune(t[start-1]) {
+			score += 20
+		}
+		return score, positions, true
+	}
+
+	var positions []int
+	score := 0
+	for i, j := 0, 0; j < len(q); i++ {
+		if i == len(t) {
+			return 0, nil, false
+		}
+		if t[i] != q[j] {
+			continue
+		}
+		score++
+		if i == 0 || !isWordRune(t[i-1]) {
+			score += 3
+		}
+		if len(positions) > 0 && positions[len(positions)-1] == i-1 {
+			score += 5
+		}
+		positions = append(positions, i)
+		j++
+	}
+	return score, positions, true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// aliasMatch is how a definition matched a search: its score and, for each
+// field that matched, the positions to highlight.
+type aliasMatch struct {
+	score  int
+	fields map[string][]int
+}
+
+// searchAlias matches query against the name, command, description and tags
+// of alias. A match on the name counts most, then the tags.
+func searchAlias(query string, alias Alias) (aliasMatch, bool) {
+	m := aliasMatch{fields: map[string][]int{}}
+	if query == "" {
+		return m, true
+	}
+	fields := []struct {
+		name   string
+		text   string
+		weight int
+	}{
+		{"name", alias.Name, 3},
+		{"tags", strings.Join(alias.Tags, ", "), 2},
+		{"description", alias.Description, 1},
+		{"command", alias.Command, 1},
+	}
+	found := false
+	for _, f := range fields {
+		score, positions, ok := fuzzyMatch(query, f.text)
+		if !ok {
+			continue
+		}
+		found = true
+		m.fields[f.name] = positions
+		if score*f.weight > m.score {
+			m.score = score * f.weight
+		}
+	}
+	return m, found
+}
+
+// highlight returns text escaped for a tview table cell, with the runes at
+// positions emphasized.
+func highlight(text string, positions []int) string {
+	if len(positions) == 0 {
+		return tview.Escape(text)
+	}
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+
+	var b, plain strings.Builder
+	for i, r := range []rune(text) {
+		if !marked[i] {
+			plain.WriteRune(r)
+			continue
+		}
+		b.WriteString(tview.Escape(plain.String()))
+		plain.Reset()
+		b.WriteString("[yellow::b]" + tview.Escape(string(r)) + "[-::-]")
+	}
+	b.WriteString(tview.Escape(plain.String()))
+	return b.String()
+}
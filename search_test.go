@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query, text string
+		ok          bool
+		positions   []int
+	}{
+		{"", "anything", true, nil},
+		{"st", "git status", true, []int{4, 5}},
+		{"GS", "git status", true, []int{0, 4}},
+		{"gst", "git status", true, []int{0, 4, 5}},
+		{"sg", "git status", false, nil},
+		{"é", "café au lait", true, []int{3}},
+		{"xyz", "", false, nil},
+	}
+	for _, tt := range tests {
+		_, positions, ok := fuzzyMatch(tt.query, tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, %v; want %v, %v", tt.query, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyMatchScore(t *testing.T) {
+	// Each query matches the first text better than the second.
+	tests := []struct {
+		query, better, worse string
+	}{
+		{"stat", "git status", "s-t-a-t"},
+		{"log", "git log", "catalog"},
+		{"gs", "git status", "gauss"},
+		{"gst", "git status", "gust"},
+	}
+	for _, tt := range tests {
+		better, _, ok1 := fuzzyMatch(tt.query, tt.better)
+		worse, _, ok2 := fuzzyMatch(tt.query, tt.worse)
+		if !ok1 || !ok2 || better <= worse {
+			t.Errorf("%q scores %d on %q and %d on %q", tt.query, better, tt.better, worse, tt.worse)
+		}
+	}
+}
+
+func TestSearchAlias(t *testing.T) {
+	alias := Alias{Name: "gp", Command: "git push", Description: "publish the branch", Tags: []string{"git", "remote"}}
+	tests := []struct {
+		query  string
+		ok     bool
+		fields []string
+	}{
+		{"gp", true, []string{"name", "command"}},
+		{"remote", true, []string{"tags"}},
+		{"publish", true, []string{"description"}},
+		{"push", true, []string{"description", "command"}},
+		{"docker", false, nil},
+	}
+	for _, tt := range tests {
+		m, ok := searchAlias(tt.query, alias)
+		var fields []string
+		for _, field := range []string{"name", "tags", "description", "command"} {
+			if _, matched := m.fields[field]; matched {
+				fields = append(fields, field)
+			}
+		}
+		if ok != tt.ok || !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("searchAlias(%q) matched %v, %v; want %v, %v", tt.query, fields, ok, tt.fields, tt.ok)
+		}
+	}
+
+	// A match on the name ranks above a match on the command.
+	byName, _ := searchAlias("ls", Alias{Name: "ls", Command: "exa"})
+	byCommand, _ := searchAlias("ls", Alias{Name: "l", Command: "ls -l"})
+	if byName.score <= byCommand.score {
+		t.Errorf("name match scores %d, command match %d", byName.score, byCommand.score)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text      string
+		positions []int
+		want      string
+	}{
+		{"plain", nil, "plain"},
+		{"git status", []int{4, 5}, "git [yellow::b]s[-::-][yellow::b]t[-::-]atus"},
+		{"[x]", []int{1}, "[[yellow::b]x[-::-]]"},
+	}
+	for _, tt := range tests {
+		if got := highlight(tt.text, tt.positions); got != tt.want {
+			t.Errorf("highlight(%q, %v) = %q, want %q", tt.text, tt.positions, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const defaultModel = "llama3:8b"
+
+// storeVersion is the version of the store format written by this build.
+const storeVersion = 1
+
+// storeFile is the layout of the store on disk.
+type storeFile struct {
+	Version  int     `json:"version"`
+	Settings Config  `json:"settings"`
+	Aliases  []Alias `json:"aliases"`
+	// DisabledGroups are left out of the generated files as a whole,
+	// whether their definitions are disabled or not.
+	DisabledGroups []string `json:"disabled_groups,omitempty"`
+}
+
+// AliasStore is the single entry point for reading and changing aliases and
+// settings. It is backed by a JSON file, the source of truth from which the
+// alias file and the scripts of other shells are generated.
+type AliasStore struct {
+	path string
+	data storeFile
+}
+
+// loadAliasStore reads the store at path.
+func loadAliasStore(path string) (*AliasStore, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w; add an alias or run aliasman install to create it", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	store := &AliasStore{path: path}
+	if err := json.Unmarshal(content, &store.data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if store.data.Version > storeVersion {
+		return nil, fmt.Errorf("%s was written by a newer version of aliasman", path)
+	}
+	return store, nil
+}
+
+// editAliasStore loads the store, applies edit and saves the result, holding
+// the store lock throughout so that concurrent edits are not lost. If edit
+// changed anything, the previous store is backed up first and the change is
+// recorded in the journal, both under reason, which describes the change. In
+// git history mode the change is also committed with reason as the message.
+// On a fresh machine the first edit creates the store.
+func editAliasStore(paths appPaths, reason string, edit func(*AliasStore) error) error {
+	if err := createStore(paths.Store, paths.AliasFile); err != nil {
+		return err
+	}
+	return changeStore(paths, reason, edit, func(before, after []byte) error {
+		return recordChange(paths.StateDir, reason, before, after)
+	})
+}
+
+// changeStore is editAliasStore with a choice of what to do once a change is
+// saved: committed is called with the store before and after it, while the
+// lock is still held.
+func changeStore(paths appPaths, reason string, edit func(*AliasStore) error, committed func(before, after []byte) error) error {
+	unlock, err := lockStore(paths.Store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		return err
+	}
+	before, err := store.marshal()
+	if err != nil {
+		return err
+	}
+	// Turning git history off is committed too, leaving the repository clean.
+	gitHistory := store.Config().GitHistory
+	if err := edit(store); err != nil {
+		return err
+	}
+
+	after, err := store.marshal()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		// Saving still regenerates files that may be missing.
+		return store.Save()
+	}
+	if err := backupFiles(paths.StateDir, reason, []string{paths.Store}); err != nil {
+		return fmt.Errorf("error backing up %s: %w", paths.Store, err)
+	}
+	// A change that reached the store is recorded and committed even if its
+	// files could not be generated, and recorded even if committing fails,
+	// so that it can still be undone.
+	saveErr := store.Save()
+	var genErr *generateError
+	if saveErr != nil && !errors.As(saveErr, &genErr) {
+		return saveErr
+	}
+	if err := committed(before, after); err != nil {
+		return err
+	}
+	if gitHistory || store.Config().GitHistory {
+		if err := commitStore(paths.Store, reason); err != nil {
+			return fmt.Errorf("the change was saved, but committing it to git failed: %w", err)
+		}
+	}
+	return saveErr
+}
+
+// createStore writes a new store holding only the reload alias, leaving an
+// existing one untouched.
+func createStore(storePath, aliasFilePath string) error {
+	unlock, err := lockStore(storePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if fileExists(storePath) {
+		return nil
+	}
+	store := &AliasStore{path: storePath}
+	reload := Alias{Name: reloadAliasName, Command: "source " + shellQuote(aliasFilePath), Type: "alias"}
+	if err := store.Add(reload); err != nil {
+		return err
+	}
+	config := store.Config()
+	config.AliasFile = aliasFilePath
+	if err := store.SetConfig(config); err != nil {
+		return err
+	}
+	return store.Save()
+}
+
+// Aliases returns every alias and function in store order, each with the
+// line of the alias file it is generated on.
+func (s *AliasStore) Aliases() []Alias {
+	aliases := slices.Clone(s.data.Aliases)
+	lines := aliasFileLines(s.markDisabledGroups(slices.Clone(aliases)), s.Config())
+	for i := range aliases {
+		aliases[i].Line = lines[aliases[i].Name]
+	}
+	return aliases
+}
+
+// EffectiveAliases returns Aliases with the definitions in disabled groups
+// marked as disabled, as they are written to the generated files.
+func (s *AliasStore) EffectiveAliases() []Alias {
+	return s.markDisabledGroups(s.Aliases())
+}
+
+func (s *AliasStore) markDisabledGroups(aliases []Alias) []Alias {
+	for i := range aliases {
+		if s.GroupDisabled(aliases[i].Group) {
+			aliases[i].Disabled = true
+		}
+	}
+	return aliases
+}
+
+// Get returns the definition called name.
+func (s *AliasStore) Get(name string) (Alias, bool) {
+	if i := s.index(name); i >= 0 {
+		return s.Aliases()[i], true
+	}
+	return Alias{}, false
+}
+
+// Add appends a new definition, stamped with the current time and user.
+func (s *AliasStore) Add(alias Alias) error {
+	if err := validateAlias(alias); err != nil {
+		return err
+	}
+	if s.index(alias.Name) >= 0 {
+		return fmt.Errorf("%q is already defined", alias.Name)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	alias.Created, alias.Modified, alias.Author = &now, &now, currentAuthor()
+	s.data.Aliases = append(s.data.Aliases, alias)
+	return nil
+}
+
+// Update replaces the definition called name with alias, in place. Its
+// creation time, author and whether it is disabled are kept, and the
+// modification time is updated if anything changed.
+func (s *AliasStore) Update(name string, alias Alias) error {
+	if err := validateAlias(alias); err != nil {
+		return err
+	}
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("%q is not defined", name)
+	}
+	if alias.Name != name && s.index(alias.Name) >= 0 {
+		return fmt.Errorf("%q is already defined", alias.Name)
+	}
+	s.replace(i, alias, s.data.Aliases[i].Disabled)
+	return nil
+}
+
+// replace puts alias at index i with the given state, keeping the creation
+// time and author of the definition there.
+func (s *AliasStore) replace(i int, alias Alias, disabled bool) {
+	old := s.data.Aliases[i]
+	alias.Created, alias.Modified, alias.Author = old.Created, old.Modified, old.Author
+	alias.Disabled = disabled
+	if !sameDefinition(old, alias) {
+		now := time.Now().UTC().Truncate(time.Second)
+		alias.Modified = &now
+	}
+	s.data.Aliases[i] = alias
+}
+
+// Remove deletes the definition called name.
+func (s *AliasStore) Remove(name string) error {
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("%q is not defined", name)
+	}
+	s.data.Aliases = slices.Delete(s.data.Aliases, i, i+1)
+	return nil
+}
+
+// SetDisabled disables or enables the definition called name.
+func (s *AliasStore) SetDisabled(name string, disabled bool) error {
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("%q is not defined", name)
+	}
+	s.replace(i, s.data.Aliases[i], disabled)
+	return nil
+}
+
+// GroupDisabled reports whether the group called name is disabled.
+func (s *AliasStore) GroupDisabled(name string) bool {
+	return name != "" && slices.Contains(s.data.DisabledGroups, name)
+}
+
+// SetGroupDisabled disables or enables the group called name, leaving the
+// state of its definitions alone.
+func (s *AliasStore) SetGroupDisabled(name string, disabled bool) error {
+	if !slices.Contains(groupNames(s.data.Aliases), name) {
+		return fmt.Errorf("there is no group %q", name)
+	}
+	s.data.DisabledGroups = slices.DeleteFunc(s.data.DisabledGroups, func(group string) bool {
+		return group == name
+	})
+	if disabled {
+		s.data.DisabledGroups = append(s.data.DisabledGroups, name)
+		slices.Sort(s.data.DisabledGroups)
+	}
+	return nil
+}
+
+func (s *AliasStore) index(name string) int {
+	return slices.IndexFunc(s.data.Aliases, func(alias Alias) bool {
+		return alias.Name == name
+	})
+}
+
+// Config returns the settings kept in the store.
+func (s *AliasStore) Config() Config {
+	config := s.data.Settings
+	config.RCFiles = slices.Clone(config.RCFiles)
+	if config.Model == "" {
+		config.Model = defaultModel
+	}
+	return config
+}
+
+// SetConfig replaces the settings.
+func (s *AliasStore) SetConfig(config Config) error {
+	s.data.Settings = config
+	return nil
+}
+
+// Save writes the store back to disk and regenerates the alias file and the
+// scripts of shells that cannot source it. The generated files are rendered
+// first, so that the store is not changed when they cannot be; if writing
+// them fails once the store is written, the error is a *generateError.
+func (s *AliasStore) Save() error {
+	content, err := s.marshal()
+	if err != nil {
+		return err
+	}
+	files, err := renderGeneratedFiles(s.EffectiveAliases(), s.Config())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, content, 0644); err != nil {
+		return err
+	}
+	if err := writeGeneratedFiles(files); err != nil {
+		return &generateError{err}
+	}
+	return nil
+}
+
+// generateError is returned by Save when the store was written but the
+// generated files were not, leaving them out of date until the next save.
+type generateError struct {
+	err error
+}
+
+func (e *generateError) Error() string {
+	return fmt.Sprintf("the change was saved, but generating the alias files failed: %v", e.err)
+}
+
+func (e *generateError) Unwrap() error {
+	return e.err
+}
+
+func (s *AliasStore) marshal() ([]byte, error) {
+	s.data.Version = storeVersion
+	if s.data.Aliases == nil {
+		s.data.Aliases = []Alias{}
+	}
+	// A group is gone with its last definition, and so is its state.
+	groups := groupNames(s.data.Aliases)
+	s.data.DisabledGroups = slices.DeleteFunc(s.data.DisabledGroups, func(group string) bool {
+		return !slices.Contains(groups, group)
+	})
+	content, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// formatAlias renders a definition as it is written to the alias file.
+func formatAlias(alias Alias) string {
+	switch alias.Type {
+	case "function":
+		return fmt.Sprintf("function %s() {\n%s\n}\n", alias.Name, alias.Command)
+	case "global", "suffix":
+		return zshGuard + formatZshAlias(alias)
+	}
+	return fmt.Sprintf("alias %s=%s\n", alias.Name, shellQuote(alias.Command))
+}
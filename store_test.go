@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testPaths returns the paths of an instance isolated in a temporary
+// directory.
+func testPaths(t *testing.T) appPaths {
+	t.Helper()
+	t.Setenv("ALIASMAN_HOME", t.TempDir())
+	paths, err := resolvePaths(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+// storeNames returns the names of the definitions in the store at path,
+// marking disabled ones with a "!".
+func storeNames(t *testing.T, path string) []string {
+	t.Helper()
+	store, err := loadAliasStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, alias := range store.Aliases() {
+		if alias.Disabled {
+			names = append(names, "!"+alias.Name)
+		} else {
+			names = append(names, alias.Name)
+		}
+	}
+	return names
+}
+
+func TestAliasStore(t *testing.T) {
+	store := &AliasStore{path: filepath.Join(t.TempDir(), storeFileName)}
+	steps := []struct {
+		desc string
+		op   func(*AliasStore) error
+		err  string // a part of the error, or "" if the step succeeds
+	}{
+		{"add ll", func(s *AliasStore) error {
+			return s.Add(Alias{Name: "ll", Type: "alias", Command: "ls -l"})
+		}, ""},
+		{"add greet", func(s *AliasStore) error {
+			return s.Add(Alias{Name: "greet", Type: "function", Command: "echo hi"})
+		}, ""},
+		{"add ll again", func(s *AliasStore) error {
+			return s.Add(Alias{Name: "ll", Type: "alias", Command: "ls -la"})
+		}, "already defined"},
+		{"add an invalid alias", func(s *AliasStore) error {
+			return s.Add(Alias{Name: "a b", Type: "alias", Command: "x"})
+		}, "not a valid alias name"},
+		{"disable ll", func(s *AliasStore) error {
+			return s.SetDisabled("ll", true)
+		}, ""},
+		{"update ll", func(s *AliasStore) error {
+			return s.Update("ll", Alias{Name: "ll", Type: "alias", Command: "ls -lh"})
+		}, ""},
+		{"rename greet onto ll", func(s *AliasStore) error {
+			return s.Update("greet", Alias{Name: "ll", Type: "function", Command: "echo hi"})
+		}, "already defined"},
+		{"rename greet", func(s *AliasStore) error {
+			return s.Update("greet", Alias{Name: "hello", Type: "function", Command: "echo hi"})
+		}, ""},
+		{"update a missing alias", func(s *AliasStore) error {
+			return s.Update("nope", Alias{Name: "nope", Type: "alias", Command: "x"})
+		}, "not defined"},
+		{"add la", func(s *AliasStore) error {
+			return s.Add(Alias{Name: "la", Type: "alias", Command: "ls -a"})
+		}, ""},
+		{"remove hello", func(s *AliasStore) error {
+			return s.Remove("hello")
+		}, ""},
+		{"remove hello again", func(s *AliasStore) error {
+			return s.Remove("hello")
+		}, "not defined"},
+		{"enable a missing alias", func(s *AliasStore) error {
+			return s.SetDisabled("hello", false)
+		}, "not defined"},
+	}
+	for _, step := range steps {
+		err := step.op(store)
+		switch {
+		case step.err == "" && err != nil:
+			t.Fatalf("%s: %v", step.desc, err)
+		case step.err != "" && (err == nil || !strings.Contains(err.Error(), step.err)):
+			t.Fatalf("%s: got error %v, want one containing %q", step.desc, err, step.err)
+		}
+	}
+
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := storeNames(t, store.path), []string{"!ll", "la"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+	ll, _ := store.Get("ll")
+	if ll.Command != "ls -lh" || ll.Created == nil || ll.Author == "" {
+		t.Errorf("ll = %+v, want the updated command with its creation time and author kept", ll)
+	}
+}
+
+func TestAliasStoreLines(t *testing.T) {
+	dir := t.TempDir()
+	store := &AliasStore{path: filepath.Join(dir, storeFileName)}
+	config := store.Config()
+	config.AliasFile = filepath.Join(dir, "aliases.sh")
+	store.SetConfig(config)
+	for _, alias := range []Alias{
+		{Name: "a", Type: "alias", Command: "x", Group: "g"},
+		{Name: "f", Type: "function", Command: "echo 1\necho 2"},
+		{Name: "b", Type: "alias", Command: "y"},
+	} {
+		if err := store.Add(alias); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each definition is reported on the line of the alias file it starts on.
+	script, err := os.ReadFile(config.AliasFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(script), "\n")
+	for _, alias := range store.Aliases() {
+		want, _, _ := strings.Cut(formatAlias(alias), "\n")
+		if alias.Line < 1 || alias.Line > len(lines) || lines[alias.Line-1] != want {
+			t.Errorf("%s is reported on line %d, want the line holding %q in\n%s", alias.Name, alias.Line, want, script)
+		}
+	}
+}
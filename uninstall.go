@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// fileChange is a pending rewrite of a file, kept so it can be shown as a
+// diff before it is applied.
+type fileChange struct {
+	Path   string
+	Before string
+	After  string
+	Delete bool
+}
+
+func (c fileChange) Diff() string {
+	return unifiedDiff(c.Path, c.Before, c.After)
+}
+
+// planUninstall returns the changes that remove the managed block from every
+// shell config file that has one, looking at the recorded files as well as,
+// unless the instance is relocated, those aliasman knows about in homeDir, and
+// that delete the generated alias file and scripts.
+func planUninstall(paths appPaths, homeDir string) ([]fileChange, error) {
+	var config Config
+	if store, err := loadAliasStore(paths.Store); err == nil {
+		config = store.Config()
+	}
+
+	var changes []fileChange
+	if config.AliasFile != "" && isGeneratedFile(config.AliasFile) {
+		content, err := os.ReadFile(config.AliasFile)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, fileChange{Path: config.AliasFile, Before: string(content), Delete: true})
+	}
+
+	rcFiles := config.RCFiles
+	if !paths.relocated() {
+		for _, path := range shellConfigCandidates(homeDir) {
+			if !slices.Contains(rcFiles, path) {
+				rcFiles = append(rcFiles, path)
+			}
+		}
+	}
+	for _, path := range rcFiles {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if generatedShell(path) != "" {
+			if isGeneratedFile(path) {
+				changes = append(changes, fileChange{Path: path, Before: string(content), Delete: true})
+			}
+			continue
+		}
+		if after := withoutManagedBlock(string(content)); after != string(content) {
+			changes = append(changes, fileChange{Path: path, Before: string(content), After: after})
+		}
+	}
+	return changes, nil
+}
+
+// applyChanges writes the planned changes, refusing to overwrite a file that
+// was modified since the change was planned.
+func applyChanges(changes []fileChange) error {
+	for _, c := range changes {
+		content, err := os.ReadFile(c.Path)
+		if err != nil {
+			return err
+		}
+		if string(content) != c.Before {
+			return fmt.Errorf("%s was modified in the meantime; nothing was written to it", c.Path)
+		}
+		if c.Delete {
+			err = os.Remove(c.Path)
+		} else {
+			err = writeFileAtomic(c.Path, []byte(c.After), 0644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uninstallAliasman backs up and applies the planned changes, forgets the
+// alias file and the recorded shell config files and, if archive is set,
+// moves the store aside, returning its new path.
+func uninstallAliasman(paths appPaths, changes []fileChange, archive bool) (string, error) {
+	var changed []string
+	for _, c := range changes {
+		changed = append(changed, c.Path)
+	}
+	if err := backupFiles(paths.StateDir, "uninstall", changed); err != nil {
+		return "", fmt.Errorf("error backing up files: %w", err)
+	}
+	if err := applyChanges(changes); err != nil {
+		return "", err
+	}
+
+	if fileExists(paths.Store) {
+		err := changeStore(paths, "uninstall", func(store *AliasStore) error {
+			config := store.Config()
+			config.AliasFile = ""
+			config.RCFiles = nil
+			return store.SetConfig(config)
+		}, notJournaled)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !archive {
+		return "", nil
+	}
+	return archiveStore(paths.Store)
+}
+
+// withoutManagedBlock removes every managed block from content, together with
+// the blank line installAliasman puts in front of it.
+func withoutManagedBlock(content string) string {
+	for {
+		start, end, ok := findManagedBlock(content)
+		if !ok {
+			return content
+		}
+		before := content[:start]
+		if strings.HasSuffix(before, "\n\n") || before == "\n" {
+			before = before[:len(before)-1]
+		}
+		content = before + content[end:]
+	}
+}
+
+// archiveStore moves the store aside and returns its new path.
+func archiveStore(storePath string) (string, error) {
+	archivePath := fmt.Sprintf("%s.archived-%s", storePath, time.Now().Format("20060102-150405"))
+	if err := os.Rename(storePath, archivePath); err != nil {
+		return "", err
+	}
+	return archivePath, nil
+}
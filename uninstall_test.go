@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithoutManagedBlock(t *testing.T) {
+	block := managedBlock("/home/u/.aliasman_aliases")
+	tests := []struct {
+		name, content, want string
+	}{
+		{"no block", "export A=1\n", "export A=1\n"},
+		{"only the block", block, ""},
+		{"with the blank line in front", "export A=1\n\n" + block, "export A=1\n"},
+		{"in the middle", "a\n" + block + "b\n", "a\nb\n"},
+		{"several blocks", block + "a\n\n" + block, "a\n"},
+		{"start tag alone", tagStart + "\na\n", tagStart + "\na\n"},
+	}
+	for _, tt := range tests {
+		if got := withoutManagedBlock(tt.content); got != tt.want {
+			t.Errorf("%s: withoutManagedBlock(%q) = %q, want %q", tt.name, tt.content, got, tt.want)
+		}
+	}
+
+	// Uninstalling undoes installing, up to a missing final newline.
+	for content, want := range map[string]string{"": "", "export A=1\n": "export A=1\n", "export A=1": "export A=1\n"} {
+		if got := withoutManagedBlock(withManagedBlock(content, "/x")); got != want {
+			t.Errorf("installing and uninstalling turned %q into %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestPlanUninstallRelocated(t *testing.T) {
+	paths := testPaths(t)
+	home := t.TempDir()
+	dir := t.TempDir()
+	rc := filepath.Join(dir, ".bashrc")
+	if err := installAliasman(paths, []string{rc}); err != nil {
+		t.Fatal(err)
+	}
+	// The home directory has a block of its own, which a relocated instance
+	// must leave alone.
+	homeRC := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(homeRC, []byte(managedBlock(filepath.Join(home, aliasFileName))), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := planUninstall(paths, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Path)
+	}
+	if want := []string{paths.AliasFile, rc}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("planned changes to %v, want %v", got, want)
+	}
+	if !changes[0].Delete || changes[1].After != "" {
+		t.Errorf("changes = %+v, want the alias file deleted and the block removed", changes)
+	}
+
+	if _, err := uninstallAliasman(paths, changes, false); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(paths.AliasFile) {
+		t.Error("the alias file was not deleted")
+	}
+	store, err := loadAliasStore(paths.Store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config := store.Config(); config.AliasFile != "" || config.RCFiles != nil {
+		t.Errorf("settings = %+v, want the installation forgotten", config)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import "fmt"
+
+// zshAliasFlags are the alias options of the zsh-only alias types.
+var zshAliasFlags = map[string]string{
+	"global": "-g", // expanded anywhere on the command line, e.g. G='| grep'
+	"suffix": "-s", // runs files with the extension NAME, e.g. pdf=zathura
+}
+
+// formatZshAlias renders a global or suffix alias without the guard that
+// keeps bash from reading it.
+func formatZshAlias(alias Alias) string {
+	return fmt.Sprintf("alias %s %s=%s\n", zshAliasFlags[alias.Type], alias.Name, shellQuote(alias.Command))
+}
+
+// renderZsh renders definitions for zsh, which reads the bash syntax of the
+// alias file as well as its own alias kinds.
+func renderZsh(alias Alias, _ Config) (string, error) {
+	if _, ok := zshAliasFlags[alias.Type]; ok {
+		return formatZshAlias(alias), nil
+	}
+	return formatAlias(alias), nil
+}